@@ -3,6 +3,8 @@ package v1beta1
 import (
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -19,11 +21,140 @@ type RabbitmqClusterStatus struct {
 	// duck type. See: https://k8s-service-bindings.github.io/spec/#provisioned-service
 	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
 
+	// Disruptive actions waiting for the next maintenance window configured in spec.maintenanceWindows.
+	// +optional
+	DeferredActions []DeferredAction `json:"deferredActions,omitempty"`
+
+	// Start of the next maintenance window. Only set while actions are deferred.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+
+	// Pods whose RabbitMQ nodes are drained through the rabbitmq.com/drain annotation.
+	// A drained node is in maintenance mode: it does not accept client connections and hosts no queue leaders.
+	// +optional
+	DrainedNodes []string `json:"drainedNodes,omitempty"`
+
+	// URLs of the listeners exposed by the client Service. Only listeners enabled by the plugins and TLS settings are listed.
+	// +optional
+	Endpoints []RabbitmqClusterEndpoint `json:"endpoints,omitempty"`
+
+	// Feature flags enabled and disabled on the RabbitMQ cluster, as reported by `rabbitmqctl list_feature_flags`.
+	// Only set when spec.rabbitmq.featureFlags is configured.
+	FeatureFlags *RabbitmqClusterFeatureFlagsStatus `json:"featureFlags,omitempty"`
+
+	// Image of the RabbitMQ containers, after applying the default image and the image registry rewrites of the operator.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// URL of the management UI, if exposed through spec.management.ingress.
+	// +optional
+	ManagementURL string `json:"managementURL,omitempty"`
+
+	// True if a change of the server configuration requires the RabbitMQ nodes to be restarted, until the rolling restart of all nodes is complete.
+	// Settings which RabbitMQ can change at runtime, such as the memory high watermark, the disk free limit and the log levels,
+	// are applied to the running nodes without a restart.
+	// +optional
+	PendingRestart bool `json:"pendingRestart,omitempty"`
+
+	// Services created for spec.services.
+	// +optional
+	Services []RabbitmqClusterNamedServiceStatus `json:"services,omitempty"`
+
+	// State of each RabbitMQ node, refreshed at the interval configured in the operator configuration.
+	// The state as seen by RabbitMQ is unknown if no RabbitMQ node is ready.
+	// +optional
+	Nodes []RabbitmqClusterNodeStatus `json:"nodes,omitempty"`
+
+	// Time at which RabbitMQ was last queried for the state of the nodes in status.nodes.
+	// +optional
+	NodesRefreshedAt *metav1.Time `json:"nodesRefreshedAt,omitempty"`
+
+	// Progress of the recovery of a RabbitMQ node which lost its data. Not set if no recovery is in progress.
+	// The operator resumes the recovery from this state after a restart.
+	// +optional
+	NodeRecovery *RabbitmqClusterNodeRecoveryStatus `json:"nodeRecovery,omitempty"`
+
+	// Stage of the persistent volume expansion in progress. Not set if no expansion is in progress.
+	// The operator resumes the expansion from this stage after a restart.
+	// +optional
+	PersistenceExpansionStage PersistenceExpansionStage `json:"persistenceExpansionStage,omitempty"`
+
+	// Pods whose volumes have been expanded, but whose file systems are only resized when the Pods are restarted
+	// because the volume plugin does not support online expansion. The expansion completes once these Pods are restarted,
+	// e.g. with kubectl delete pod.
+	// +optional
+	PersistenceExpansionPendingRestart []string `json:"persistenceExpansionPendingRestart,omitempty"`
+
+	// Progress of the StorageClass migration. Not set if no migration is in progress.
+	// The operator resumes the migration from this state after a restart.
+	// +optional
+	StorageClassMigration *RabbitmqClusterStorageClassMigrationStatus `json:"storageClassMigration,omitempty"`
+
+	// Zone of each RabbitMQ node, as given by the topology.kubernetes.io/zone label of the Kubernetes node of the Pod.
+	// Pods which are not scheduled yet are not listed.
+	// +optional
+	Zones []RabbitmqClusterNodeZone `json:"zones,omitempty"`
+
 	// observedGeneration is the most recent successful generation observed for this RabbitmqCluster. It corresponds to the
 	// RabbitmqCluster's generation, which is updated on mutation by the API Server.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
+type RabbitmqClusterNamedServiceStatus struct {
+	// Name of the Kubernetes Service.
+	Name string `json:"name"`
+	// Type of the Kubernetes Service.
+	Type corev1.ServiceType `json:"type"`
+	// Listeners exposed by the Kubernetes Service.
+	// +optional
+	Listeners []string `json:"listeners,omitempty"`
+}
+
+type RabbitmqClusterEndpoint struct {
+	// Name of the listener, as named in the ports of the client Service, e.g. amqps.
+	Listener string `json:"listener"`
+	// URL using the cluster-internal DNS name of the client Service.
+	Internal string `json:"internal"`
+	// URLs using the ingress addresses of the client Service if it is of type LoadBalancer.
+	// +optional
+	External []string `json:"external,omitempty"`
+}
+
+type NodeState string
+
+const (
+	NodeStateRunning          NodeState = "Running"
+	NodeStateNotRunning       NodeState = "NotRunning"
+	NodeStatePartitioned      NodeState = "Partitioned"
+	NodeStateUnderMaintenance NodeState = "UnderMaintenance"
+	NodeStateUnknown          NodeState = "Unknown"
+)
+
+type RabbitmqClusterNodeStatus struct {
+	// Name of the Pod.
+	Pod string `json:"pod"`
+	// Name of the RabbitMQ node.
+	Node string `json:"node"`
+	// Whether the Pod is ready.
+	Ready bool `json:"ready"`
+	// State of the RabbitMQ node as reported by rabbitmq-diagnostics cluster_status.
+	State NodeState `json:"state"`
+	// RabbitMQ version of the node. Not set if the node is not running.
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Zone of the Kubernetes node of the Pod.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// Name of the PersistentVolumeClaim of the Pod.
+	// +optional
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+	// Capacity of the PersistentVolume bound to the PersistentVolumeClaim.
+	// +optional
+	Capacity *k8sresource.Quantity `json:"capacity,omitempty"`
+	// Number of restarts of the RabbitMQ container.
+	RestartCount int32 `json:"restartCount"`
+}
+
 // Contains references to resources created with the RabbitmqCluster resource.
 type RabbitmqClusterDefaultUser struct {
 	// Reference to the Kubernetes Secret containing the credentials of the default
@@ -51,11 +182,116 @@ type RabbitmqClusterServiceReference struct {
 	Namespace string `json:"namespace"`
 }
 
-func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Object) {
+// Zone of a RabbitMQ node
+type RabbitmqClusterNodeZone struct {
+	// Name of the Pod
+	Pod string `json:"pod"`
+	// Name of the Kubernetes node the Pod runs on
+	Node string `json:"node"`
+	// Zone of the Kubernetes node. Empty if the Kubernetes node has no zone label.
+	Zone string `json:"zone,omitempty"`
+}
+
+// Stage of a persistent volume expansion. Must be one of: DeletingStatefulSet, Resizing.
+type PersistenceExpansionStage string
+
+const (
+	// The StatefulSet is being deleted without deleting its Pods because volume claim templates cannot be updated.
+	PersistenceExpansionDeletingStatefulSet PersistenceExpansionStage = "DeletingStatefulSet"
+	// The PersistentVolumeClaims have been updated and the StatefulSet has been recreated. The volumes are being resized.
+	PersistenceExpansionResizing PersistenceExpansionStage = "Resizing"
+)
+
+// Disruptive action deferred to a maintenance window.
+// Must be one of: StatefulSetUpdate, Rollout, Restart, PersistenceExpansion, StorageClassMigration, QueueRebalance.
+// +kubebuilder:validation:Enum=StatefulSetUpdate;Rollout;Restart;PersistenceExpansion;StorageClassMigration;QueueRebalance
+type DeferredAction string
+
+const (
+	// Update of the Pod template or the volume claim templates of the StatefulSet, e.g. for an image upgrade.
+	DeferredStatefulSetUpdate DeferredAction = "StatefulSetUpdate"
+	// Next step of a canary rollout, which updates the Pods below the current partition of the StatefulSet.
+	DeferredRollout DeferredAction = "Rollout"
+	// Rolling restart after a change of the server configuration which cannot be applied at runtime.
+	DeferredRestart DeferredAction = "Restart"
+	// Persistent volume expansion, which deletes and recreates the StatefulSet.
+	DeferredPersistenceExpansion DeferredAction = "PersistenceExpansion"
+	// StorageClass migration, which deletes and recreates the StatefulSet and replaces the volumes of each Pod.
+	DeferredStorageClassMigration DeferredAction = "StorageClassMigration"
+	// Rebalancing of the queue leaders after a rolling update.
+	DeferredQueueRebalance DeferredAction = "QueueRebalance"
+)
+
+// Progress of a StorageClass migration.
+// Pods are migrated one at a time, starting with the Pod with the highest ordinal.
+type RabbitmqClusterStorageClassMigrationStatus struct {
+	// Names of the volume claim templates whose StorageClass is migrated
+	VolumeClaimTemplates []string `json:"volumeClaimTemplates"`
+	// Current stage of the migration
+	Stage StorageClassMigrationStage `json:"stage"`
+	// Name of the Pod being migrated
+	// +optional
+	Pod string `json:"pod,omitempty"`
+	// Names of the Pods which have been migrated
+	// +optional
+	MigratedPods []string `json:"migratedPods,omitempty"`
+	// Number of replicas when the migration started. Pods added later are created with the new StorageClass.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// Stage of a StorageClass migration. Must be one of: DeletingStatefulSet, Evacuating, ReplacingVolumes, Rejoining, Syncing.
+type StorageClassMigrationStage string
+
+const (
+	// The StatefulSet is being deleted without deleting its Pods because volume claim templates cannot be updated.
+	StorageClassMigrationDeletingStatefulSet StorageClassMigrationStage = "DeletingStatefulSet"
+	// The Pod is drained and its quorum queue and stream replicas are removed.
+	StorageClassMigrationEvacuating StorageClassMigrationStage = "Evacuating"
+	// The RabbitMQ node is removed from the cluster, its PersistentVolumeClaims and the Pod are deleted.
+	StorageClassMigrationReplacingVolumes StorageClassMigrationStage = "ReplacingVolumes"
+	// The Pod is recreated with PersistentVolumeClaims of the new StorageClass and joins the cluster as a fresh node.
+	StorageClassMigrationRejoining StorageClassMigrationStage = "Rejoining"
+	// Quorum queue and stream replicas have been added to the fresh node. The migration waits until the data is replicated.
+	StorageClassMigrationSyncing StorageClassMigrationStage = "Syncing"
+)
+
+// Recovery of a RabbitMQ node which came back with an empty data directory, e.g. after its persistent volume was lost.
+type RabbitmqClusterNodeRecoveryStatus struct {
+	// Name of the Pod whose node is recovered
+	Pod string `json:"pod"`
+	// Current stage of the recovery
+	Stage NodeRecoveryStage `json:"stage"`
+}
+
+// Stage of a node recovery. Must be one of: Forgetting, Rejoining, Syncing.
+type NodeRecoveryStage string
+
+const (
+	// The node is reset, removed from the cluster by a peer, and its Pod is deleted.
+	NodeRecoveryForgetting NodeRecoveryStage = "Forgetting"
+	// The Pod is recreated and the node joins the cluster as a fresh node; once it is ready, quorum queue and stream replicas are added to it.
+	NodeRecoveryRejoining NodeRecoveryStage = "Rejoining"
+	// Quorum queue and stream replicas have been added to the node. The recovery waits until the data is replicated.
+	NodeRecoverySyncing NodeRecoveryStage = "Syncing"
+)
+
+// Feature flags enabled and disabled on the RabbitMQ cluster.
+type RabbitmqClusterFeatureFlagsStatus struct {
+	// Names of the feature flags which are enabled
+	Enabled []string `json:"enabled,omitempty"`
+	// Names of the feature flags which are disabled
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// SetConditions computes the conditions from the child resources. Configuration warnings and drained nodes are reported in the NoWarnings condition.
+// Conditions of other types, such as RolloutPaused, are kept.
+func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Object, configWarnings ...string) {
 	var oldAllPodsReadyCondition *status.RabbitmqClusterCondition
 	var oldClusterAvailableCondition *status.RabbitmqClusterCondition
 	var oldNoWarningsCondition *status.RabbitmqClusterCondition
 	var oldReconcileCondition *status.RabbitmqClusterCondition
+	var otherConditions []status.RabbitmqClusterCondition
 
 	for _, condition := range clusterStatus.Conditions {
 		switch condition.Type {
@@ -67,12 +303,14 @@ func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Ob
 			oldNoWarningsCondition = condition.DeepCopy()
 		case status.ReconcileSuccess:
 			oldReconcileCondition = condition.DeepCopy()
+		default:
+			otherConditions = append(otherConditions, condition)
 		}
 	}
 
 	allReplicasReadyCond := status.AllReplicasReadyCondition(resources, oldAllPodsReadyCondition)
 	clusterAvailableCond := status.ClusterAvailableCondition(resources, oldClusterAvailableCondition)
-	noWarningsCond := status.NoWarningsCondition(resources, oldNoWarningsCondition)
+	noWarningsCond := status.NoWarningsCondition(resources, oldNoWarningsCondition, clusterStatus.DrainedNodes, configWarnings...)
 
 	var reconciledCondition status.RabbitmqClusterCondition
 	if oldReconcileCondition != nil {
@@ -87,6 +325,7 @@ func (clusterStatus *RabbitmqClusterStatus) SetConditions(resources []runtime.Ob
 		noWarningsCond,
 		reconciledCondition,
 	}
+	clusterStatus.Conditions = append(clusterStatus.Conditions, otherConditions...)
 }
 
 func (clusterStatus *RabbitmqClusterStatus) SetCondition(condType status.RabbitmqClusterConditionType,
@@ -61,6 +61,19 @@ var _ = Describe("RabbitmqClusterStatus", func() {
 		Expect(rabbitmqClusterStatus.Conditions[3].Type).To(Equal(status.ReconcileSuccess))
 	})
 
+	It("keeps conditions of other types", func() {
+		rolloutPaused := status.RolloutPausedCondition(corev1.ConditionTrue, "HealthCheckFailed", "some-message")
+		rabbitmqClusterStatus := RabbitmqClusterStatus{
+			Conditions: []status.RabbitmqClusterCondition{rolloutPaused},
+		}
+
+		rabbitmqClusterStatus.SetConditions([]runtime.Object{&appsv1.StatefulSet{}, &corev1.Endpoints{}})
+
+		Expect(rabbitmqClusterStatus.Conditions).To(HaveLen(5))
+		Expect(rabbitmqClusterStatus.Conditions[3].Type).To(Equal(status.ReconcileSuccess))
+		Expect(rabbitmqClusterStatus.Conditions[4]).To(Equal(rolloutPaused))
+	})
+
 	It("updates an arbitrary condition", func() {
 		someCondition := status.RabbitmqClusterCondition{}
 		someCondition.Type = "a-type"
@@ -15,6 +15,8 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -48,6 +50,7 @@ type RabbitmqClusterSpec struct {
 	Replicas *int32 `json:"replicas,omitempty"`
 	// Image is the name of the RabbitMQ docker image to use for RabbitMQ nodes in the RabbitmqCluster.
 	// Must be provided together with ImagePullSecrets in order to use an image in a private registry.
+	// If set to an empty string, the default RabbitMQ image of the operator configuration is used.
 	// +kubebuilder:default:="rabbitmq:3.8.16-management"
 	Image string `json:"image,omitempty"`
 	// List of Secret resource containing access credentials to the registry for the RabbitMQ image. Required if the docker registry is private.
@@ -82,6 +85,223 @@ type RabbitmqClusterSpec struct {
 	// +kubebuilder:validation:Minimum:=0
 	// +kubebuilder:default:=604800
 	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// NetworkPolicy restricting ingress to the RabbitMQ Pods. No NetworkPolicy is created if not set.
+	// +optional
+	NetworkPolicy *RabbitmqClusterNetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// Prometheus Operator monitor scraping the RabbitMQ nodes.
+	// The monitor is only created if the monitoring.coreos.com CRDs are installed.
+	// +optional
+	Monitoring RabbitmqClusterMonitoringSpec `json:"monitoring,omitempty"`
+	// Spreads the RabbitMQ nodes across zones and hosts. Replaces the default topologySpreadConstraint,
+	// which spreads the nodes across zones if possible.
+	// +optional
+	Topology *RabbitmqClusterTopologySpec `json:"topology,omitempty"`
+	// Creates a Service for each Pod, which makes each RabbitMQ node reachable from outside the Kubernetes cluster,
+	// e.g. for stream clients, which connect to specific nodes. If the rabbitmq_stream plugin is enabled,
+	// each node advertises the address of its Service to stream clients.
+	// +optional
+	PerPodService *RabbitmqClusterPerPodServiceSpec `json:"perPodService,omitempty"`
+	// Exposure of the management UI.
+	// +optional
+	Management *RabbitmqClusterManagementSpec `json:"management,omitempty"`
+	// Additional client Services, each exposing a chosen set of listeners, e.g. AMQP behind an internal load balancer
+	// and MQTT behind a public load balancer. The Services are named <cluster name>-<name> and listed in status.services.
+	// +listType:=map
+	// +listMapKey:=name
+	// +optional
+	Services []RabbitmqClusterNamedServiceSpec `json:"services,omitempty"`
+	// Security context of the RabbitMQ Pods. If not set, the Pods run as user and group 999,
+	// and the setup container runs as root to change the owner of the files on the volumes to 999.
+	// +optional
+	Security *RabbitmqClusterSecuritySpec `json:"security,omitempty"`
+	// Time ranges in which the operator applies disruptive changes: updates of the Pod template or the volume claim templates
+	// of the StatefulSet, steps of canary rollouts, rolling restarts after configuration changes, persistent volume expansions,
+	// StorageClass migrations, and queue rebalancing. Outside the windows, these actions are deferred and listed in status.deferredActions.
+	// If no window is configured, changes are applied immediately. Setting the annotation rabbitmq.com/forceMaintenance: "true"
+	// on the RabbitmqCluster applies deferred actions immediately.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Rollout of changes of the Pod template, such as image upgrades and configuration changes which require a restart.
+	// +optional
+	Rollout *RabbitmqClusterRolloutSpec `json:"rollout,omitempty"`
+}
+
+type RolloutMode string
+
+const (
+	AllAtOnceRolloutMode RolloutMode = "AllAtOnce"
+	CanaryRolloutMode    RolloutMode = "Canary"
+)
+
+type RabbitmqClusterRolloutSpec struct {
+	// AllAtOnce lets the StatefulSet controller update all Pods, one at a time, from the highest to the lowest ordinal.
+	// Canary updates the Pod with the highest ordinal first, and moves the partition of the StatefulSet down in steps
+	// once the updated nodes pass health checks: the nodes run without local alarms and all nodes of the cluster are running.
+	// If a check fails, the rollout pauses and the RolloutPaused condition is set to True. The annotation
+	// rabbitmq.com/resumeRollout: "true" on the RabbitmqCluster resumes the rollout. Defaults to AllAtOnce.
+	// +kubebuilder:validation:Enum=AllAtOnce;Canary
+	// +optional
+	Mode RolloutMode `json:"mode,omitempty"`
+	// Number of Pods updated in each step after the canary. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	StepSize *int32 `json:"stepSize,omitempty"`
+	// If true, the health checks of a canary rollout also publish and get a message through a temporary queue
+	// on each updated node, using rabbitmqadmin.
+	// +optional
+	SmokeTest bool `json:"smokeTest,omitempty"`
+}
+
+// A time range starting at each activation of a cron schedule.
+type MaintenanceWindow struct {
+	// Cron expression with the fields minute, hour, day of month, month, and day of week, evaluated in UTC,
+	// e.g. "0 2 * * 6" for every Saturday at 02:00 UTC.
+	Schedule string `json:"schedule"`
+	// How long the window stays open after each activation of the schedule, e.g. "2h".
+	Duration metav1.Duration `json:"duration"`
+}
+
+type SecurityMode string
+
+const (
+	FixedUIDSecurityMode         SecurityMode = "FixedUID"
+	PlatformAssignedSecurityMode SecurityMode = "PlatformAssigned"
+)
+
+// All containers run as non-root user, without privilege escalation, without capabilities, and with the RuntimeDefault
+// seccomp profile, as required by the restricted Pod Security Standard. The volumes are writable through the fsGroup of the Pods.
+type RabbitmqClusterSecuritySpec struct {
+	// FixedUID runs the Pods with the given user, group, and fsGroup. PlatformAssigned leaves the user, group, and fsGroup
+	// to the platform, e.g. to the restricted SecurityContextConstraints of OpenShift. Defaults to FixedUID.
+	// +kubebuilder:validation:Enum=FixedUID;PlatformAssigned
+	// +optional
+	Mode SecurityMode `json:"mode,omitempty"`
+	// User of the containers in FixedUID mode. Defaults to 999.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+	// Group and fsGroup of the containers in FixedUID mode. Defaults to 999.
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+}
+
+type RabbitmqClusterManagementSpec struct {
+	// Exposes the management UI through a Gateway API HTTPRoute if the gateway.networking.k8s.io CRDs are installed,
+	// and through an Ingress otherwise. The URL of the management UI is reported in status.managementURL.
+	// +optional
+	Ingress *ManagementIngressSpec `json:"ingress,omitempty"`
+}
+
+type ManagementIngressSpec struct {
+	// Host name of the management UI.
+	Host string `json:"host"`
+	// Path under which the management UI is served, e.g. /rabbitmq. Sets management.path_prefix in the server configuration,
+	// which restarts the RabbitMQ nodes when changed. Defaults to /.
+	// +kubebuilder:validation:Pattern:=`^/`
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// IngressClassName of the Ingress. Not used for HTTPRoutes.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+	// Gateway the HTTPRoute attaches to. Required if the Gateway API CRDs are installed; otherwise no HTTPRoute is created
+	// and the ReconcileSuccess condition is set to False.
+	// +optional
+	Gateway *ManagementGatewayReference `json:"gateway,omitempty"`
+	// TLS settings of the Ingress. The management UI is served via plain HTTP if not set.
+	// +optional
+	TLS *ManagementIngressTLSSpec `json:"tls,omitempty"`
+	// Annotations to add to the Ingress or HTTPRoute.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ManagementGatewayReference struct {
+	Name string `json:"name"`
+	// Namespace of the Gateway. Defaults to the namespace of the RabbitmqCluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+type ManagementIngressTLSSpec struct {
+	// Name of the Secret holding the certificate of the host the Ingress terminates TLS with.
+	// HTTPRoutes use the certificate configured on the listener of the Gateway.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// Passes TLS connections through to the management-tls port of the RabbitMQ nodes instead of terminating TLS at the Ingress.
+	// Requires spec.tls and an ingress-nginx controller running with --enable-ssl-passthrough.
+	// Ignored for HTTPRoutes, which always terminate TLS at the Gateway.
+	// +optional
+	Passthrough bool `json:"passthrough,omitempty"`
+}
+
+// Settings of the per Pod Services. The Services expose the same ports as the client Service.
+type RabbitmqClusterPerPodServiceSpec struct {
+	// Type of the Services. Must be one of: LoadBalancer, NodePort. Defaults to LoadBalancer.
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations to add to the Services.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Topology spread constraints of the RabbitMQ Pods. The zone of each Pod is mounted at /etc/pod-info/zone
+// in the RabbitMQ container and set as the zone application environment of rabbit when the node starts.
+// The setup container waits up to 60 seconds for the zone of a new Pod.
+type RabbitmqClusterTopologySpec struct {
+	// Spreads the RabbitMQ nodes across zones, as given by the topology.kubernetes.io/zone label of the Kubernetes nodes.
+	// Defaults to a maximum skew of 1 with ScheduleAnyway, like when spec.topology is not set.
+	// +optional
+	Zones *TopologySpreadSpec `json:"zones,omitempty"`
+	// Spreads the RabbitMQ nodes across hosts, as given by the kubernetes.io/hostname label of the Kubernetes nodes.
+	// +optional
+	Hosts *TopologySpreadSpec `json:"hosts,omitempty"`
+}
+
+type TopologySpreadSpec struct {
+	// Maximum difference between the number of RabbitMQ nodes of any two zones or hosts. Defaults to 1.
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+	// DoNotSchedule leaves Pods pending if the constraint cannot be satisfied; ScheduleAnyway prefers placements
+	// which satisfy the constraint. Defaults to ScheduleAnyway.
+	// +kubebuilder:validation:Enum=DoNotSchedule;ScheduleAnyway
+	// +optional
+	WhenUnsatisfiable corev1.UnsatisfiableConstraintAction `json:"whenUnsatisfiable,omitempty"`
+}
+
+// Kind of the Prometheus Operator monitor. Must be one of: ServiceMonitor, PodMonitor, None.
+type MonitorKind string
+
+const (
+	ServiceMonitorKind MonitorKind = "ServiceMonitor"
+	PodMonitorKind     MonitorKind = "PodMonitor"
+	NoMonitorKind      MonitorKind = "None"
+)
+
+// Prometheus Operator monitor scraping the prometheus or, if TLS is enabled, the prometheus-tls port of the RabbitMQ nodes.
+type RabbitmqClusterMonitoringSpec struct {
+	// Kind of the monitor to create if the Prometheus Operator CRDs are installed. Defaults to ServiceMonitor.
+	// Set to None to not create a monitor.
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor;None
+	// +optional
+	Kind MonitorKind `json:"kind,omitempty"`
+	// Labels to add to the monitor, e.g. to match the serviceMonitorSelector or podMonitorSelector of a Prometheus.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Ingress to the RabbitMQ Pods. Inter-node ports (epmd, Erlang distribution and CLI tools) are only reachable
+// from Pods of the same RabbitmqCluster. Client ports are the ports of the client Service.
+type RabbitmqClusterNetworkPolicySpec struct {
+	// Namespace or Pod selectors of the clients allowed to connect to the client ports.
+	// Only Pods of the RabbitmqCluster can connect to the client ports if empty.
+	// +optional
+	ClientPeers []networkingv1.NetworkPolicyPeer `json:"clientPeers,omitempty"`
 }
 
 // Provides the ability to override the generated manifest of several child resources.
@@ -90,6 +310,18 @@ type RabbitmqClusterOverrideSpec struct {
 	StatefulSet *StatefulSet `json:"statefulSet,omitempty"`
 	// Override configuration for the Service created to serve traffic to the cluster.
 	Service *Service `json:"service,omitempty"`
+	// Override configuration for the PodDisruptionBudget created for clusters with more than one replica.
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// Override configuration for the PodDisruptionBudget of the RabbitMQ Pods.
+// Allows for the manifest of the created PodDisruptionBudget to be overwritten with custom configuration.
+type PodDisruptionBudget struct {
+	// +optional
+	*EmbeddedLabelsAnnotations `json:"metadata,omitempty"`
+	// Spec of the PodDisruptionBudget. Setting minAvailable unsets the default maxUnavailable.
+	// +optional
+	Spec *policyv1beta1.PodDisruptionBudgetSpec `json:"spec,omitempty"`
 }
 
 // Override configuration for the Service created to serve traffic to the cluster.
@@ -284,10 +516,24 @@ type RabbitmqClusterConfigurationSpec struct {
 	// +kubebuilder:validation:MaxItems:=100
 	AdditionalPlugins []Plugin `json:"additionalPlugins,omitempty"`
 	// Modify to add to the rabbitmq.conf file in addition to default configurations set by the operator.
-	// Modifying this property on an existing RabbitmqCluster will trigger a StatefulSet rolling restart and will cause rabbitmq downtime.
+	// Modifying this property on an existing RabbitmqCluster will trigger a StatefulSet rolling restart and will cause rabbitmq downtime,
+	// unless only settings which RabbitMQ can change at runtime are added or changed; these are applied to the running nodes.
 	// For more information on this config, see https://www.rabbitmq.com/configure.html#config-file
 	// +kubebuilder:validation:MaxLength:=2000
 	AdditionalConfig string `json:"additionalConfig,omitempty"`
+	// Settings to add to the rabbitmq.conf file, keyed by the name of the setting, e.g. `vm_memory_high_watermark.relative: "0.6"`.
+	// Known settings are validated against their value type. Settings unknown to the operator are applied,
+	// but reported in the NoWarnings condition. Settings managed by the operator, such as cluster_formation.*,
+	// listeners.* or total_memory_available_override_value, are rejected.
+	// Settings in additionalConfig take precedence over settings in config.
+	// Modifying this property on an existing RabbitmqCluster will trigger a StatefulSet rolling restart and will cause rabbitmq downtime,
+	// unless only settings which RabbitMQ can change at runtime are added or changed, such as vm_memory_high_watermark.*,
+	// disk_free_limit.*, consumer_timeout and the log levels; these are applied to the running nodes.
+	// Log levels are only applied at runtime if log.console.level and log.file.level are the same, since RabbitMQ sets the level of all log outputs at once.
+	// For more information on this config, see https://www.rabbitmq.com/configure.html#config-items
+	// +kubebuilder:validation:MaxProperties:=500
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
 	// Specify any rabbitmq advanced.config configurations to apply to the cluster.
 	// For more information on advanced config, see https://www.rabbitmq.com/configure.html#advanced-config-file
 	// +kubebuilder:validation:MaxLength:=100000
@@ -296,6 +542,72 @@ type RabbitmqClusterConfigurationSpec struct {
 	// For more information on env config, see https://www.rabbitmq.com/man/rabbitmq-env.conf.5.html
 	// +kubebuilder:validation:MaxLength:=100000
 	EnvConfig string `json:"envConfig,omitempty"`
+	// Feature flags the operator keeps enabled on the cluster. The operator compares this configuration with the output of
+	// `rabbitmqctl list_feature_flags` on every reconcile and enables any desired feature flag that is still disabled.
+	// If unset, all stable feature flags are enabled once when the cluster is created and are not managed afterwards.
+	// For more information on feature flags, see https://www.rabbitmq.com/feature-flags.html
+	// +optional
+	FeatureFlags *FeatureFlagsSpec `json:"featureFlags,omitempty"`
+	// List of community plugins to install and enable. Community plugins are not shipped with the RabbitMQ image;
+	// they are downloaded from a URL or copied from an OCI image by the init containers on every Pod start,
+	// and their checksum is verified before RabbitMQ starts.
+	// Modifying this property on an existing RabbitmqCluster will trigger a StatefulSet rolling restart.
+	// For more information on community plugins, see https://www.rabbitmq.com/community-plugins.html
+	// +kubebuilder:validation:MaxItems:=100
+	// +optional
+	CommunityPlugins []CommunityPlugin `json:"communityPlugins,omitempty"`
+}
+
+// A community plugin to install on the RabbitmqCluster. Exactly one of url or image must be set.
+type CommunityPlugin struct {
+	// Name of the plugin as used by `rabbitmq-plugins enable`, e.g. rabbitmq_message_timestamp.
+	Name Plugin `json:"name"`
+	// URL of the plugin archive (.ez file). The archive is downloaded by the setup init container.
+	// +kubebuilder:validation:Pattern:="^https?://[^\\s'\"`$\\\\]+$"
+	// +kubebuilder:validation:MaxLength=2000
+	// +optional
+	URL string `json:"url,omitempty"`
+	// OCI image containing the plugin archive. The image must provide 'sh' and 'cp'.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Path of the plugin archive within the image. Only used if image is set. Defaults to /plugins/<name>.ez
+	// +kubebuilder:validation:Pattern:="^/[\\w./-]+$"
+	// +kubebuilder:validation:MaxLength=1000
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Hex encoded SHA-256 checksum of the plugin archive.
+	// Pods fail to start if the checksum of the fetched archive does not match.
+	// +kubebuilder:validation:Pattern:="^[a-f0-9]{64}$"
+	SHA256 string `json:"sha256"`
+}
+
+// Mode in which the operator manages feature flags. Must be one of: all, explicit, none.
+// +kubebuilder:validation:Enum=all;explicit;none
+type FeatureFlagsMode string
+
+const (
+	// FeatureFlagsModeAll enables every stable feature flag supported by the RabbitMQ nodes.
+	FeatureFlagsModeAll FeatureFlagsMode = "all"
+	// FeatureFlagsModeExplicit enables only the feature flags listed in FeatureFlagsSpec.Enable.
+	FeatureFlagsModeExplicit FeatureFlagsMode = "explicit"
+	// FeatureFlagsModeNone does not enable any feature flag.
+	FeatureFlagsModeNone FeatureFlagsMode = "none"
+)
+
+// A feature flag to enable on the RabbitmqCluster.
+// +kubebuilder:validation:Pattern:="^\\w+$"
+// +kubebuilder:validation:MaxLength=100
+type FeatureFlag string
+
+// Declarative feature flag management.
+// Feature flags cannot be disabled once they are enabled. Enabled feature flags which are not part of the desired state are left untouched.
+type FeatureFlagsSpec struct {
+	// all: enable every stable feature flag. explicit: enable the feature flags listed in 'enable'. none: do not enable any feature flag.
+	// +kubebuilder:default:="all"
+	Mode FeatureFlagsMode `json:"mode,omitempty"`
+	// List of feature flags to enable. Only used when mode is set to explicit.
+	// +kubebuilder:validation:MaxItems:=100
+	Enable []FeatureFlag `json:"enable,omitempty"`
 }
 
 // The settings for the persistent storage desired for each Pod in the RabbitmqCluster.
@@ -307,6 +619,44 @@ type RabbitmqClusterPersistenceSpec struct {
 	// See https://pkg.go.dev/k8s.io/apimachinery/pkg/api/resource#Quantity for more info on the format of this field.
 	// +kubebuilder:default:="10Gi"
 	Storage *k8sresource.Quantity `json:"storage,omitempty"`
+	// Additional persistent volumes attached to each Pod in the RabbitmqCluster. Each volume stores the data of a specific role,
+	// for example the quorum queue write-ahead log, on a separate disk. All other data is stored on the volume configured by storage and storageClassName.
+	// Volumes cannot be added or removed after the RabbitmqCluster has been created, but their storage can be expanded.
+	// Adding or removing volumes is rejected and reported in the ReconcileSuccess condition.
+	// For more information on using multiple disks, see https://www.rabbitmq.com/blog/2020/04/21/quorum-queues-and-why-disks-matter/
+	// +listType=map
+	// +listMapKey=role
+	// +kubebuilder:validation:MaxItems:=4
+	// +optional
+	Volumes []PersistenceVolume `json:"volumes,omitempty"`
+}
+
+// The data stored on a persistent volume. Must be one of: quorum-wal, quorum-segments, stream-segments, logs.
+// +kubebuilder:validation:Enum=quorum-wal;quorum-segments;stream-segments;logs
+type PersistenceVolumeRole string
+
+const (
+	// PersistenceVolumeQuorumWAL stores the quorum queue write-ahead log.
+	PersistenceVolumeQuorumWAL PersistenceVolumeRole = "quorum-wal"
+	// PersistenceVolumeQuorumSegments stores the quorum queue segment files.
+	PersistenceVolumeQuorumSegments PersistenceVolumeRole = "quorum-segments"
+	// PersistenceVolumeStreamSegments stores the stream segment files.
+	PersistenceVolumeStreamSegments PersistenceVolumeRole = "stream-segments"
+	// PersistenceVolumeLogs stores the RabbitMQ log files.
+	PersistenceVolumeLogs PersistenceVolumeRole = "logs"
+)
+
+// A persistent volume attached to each Pod in the RabbitmqCluster in addition to the default persistent volume.
+type PersistenceVolume struct {
+	// The data stored on this volume. The role is also used as name of the PersistentVolumeClaim template.
+	Role PersistenceVolumeRole `json:"role"`
+	// The name of the StorageClass to claim a PersistentVolume from.
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// The requested size of the persistent volume.
+	// The format of this field matches that defined by kubernetes/apimachinery.
+	// See https://pkg.go.dev/k8s.io/apimachinery/pkg/api/resource#Quantity for more info on the format of this field.
+	// +kubebuilder:default:="10Gi"
+	Storage *k8sresource.Quantity `json:"storage,omitempty"`
 }
 
 // Settable attributes for the Service resource.
@@ -320,6 +670,31 @@ type RabbitmqClusterServiceSpec struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+type RabbitmqClusterNamedServiceSpec struct {
+	// The pattern matches DNS labels except the reserved names. They are spelled out character by character because the
+	// regular expressions of OpenAPI validation do not support lookaheads.
+
+	// Name of the Service, appended to the name of the RabbitmqCluster. The names nodes and server-<ordinal> are reserved.
+	// +kubebuilder:validation:Pattern:=`^([a-mo-rt-z]([-a-z0-9]*[a-z0-9])?|n([a-np-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|o([a-ce-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|d([a-df-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|e([a-rt-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|s[-a-z0-9]*[a-z0-9])?)?)?)?|s([a-df-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|e([a-qs-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|r([a-uw-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|v([a-df-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|e([a-qs-z0-9]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]|r([a-z0-9]([-a-z0-9]*[a-z0-9])?|-[0-9]*([a-z]([-a-z0-9]*[a-z0-9])?|-[-a-z0-9]*[a-z0-9]))?)?)?)?)?)?)$`
+	// +kubebuilder:validation:MaxLength:=40
+	Name string `json:"name"`
+	// Type of the Service. Must be one of: ClusterIP, LoadBalancer, NodePort.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;NodePort
+	// +kubebuilder:default:="ClusterIP"
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations to add to the Service.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Listeners exposed by the Service, named like the ports of the client Service. Listeners which are not enabled,
+	// e.g. mqtt without the rabbitmq_mqtt plugin, are not exposed. Exposes all listeners of the client Service if empty.
+	// +optional
+	Listeners []Listener `json:"listeners,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=amqp;amqps;management;management-tls;prometheus;prometheus-tls;mqtt;mqtts;web-mqtt;web-mqtt-tls;stomp;stomps;web-stomp;web-stomp-tls;stream;streams
+type Listener string
+
 func (cluster *RabbitmqCluster) TLSEnabled() bool {
 	return cluster.Spec.TLS.SecretName != ""
 }
@@ -336,6 +711,36 @@ func (cluster *RabbitmqCluster) SingleTLSSecret() bool {
 	return cluster.MutualTLSEnabled() && cluster.Spec.TLS.CaSecretName == cluster.Spec.TLS.SecretName
 }
 
+// MonitorKind returns the kind of the Prometheus Operator monitor to create, ServiceMonitor by default
+func (cluster *RabbitmqCluster) MonitorKind() MonitorKind {
+	if cluster.Spec.Monitoring.Kind == "" {
+		return ServiceMonitorKind
+	}
+	return cluster.Spec.Monitoring.Kind
+}
+
+// ManagementIngress returns spec.management.ingress, or nil if the management UI is not exposed.
+func (cluster *RabbitmqCluster) ManagementIngress() *ManagementIngressSpec {
+	if cluster.Spec.Management == nil {
+		return nil
+	}
+	return cluster.Spec.Management.Ingress
+}
+
+// ManagementPathPrefix returns the management.path_prefix without trailing slash; it is empty if the management UI is served under /.
+func (cluster *RabbitmqCluster) ManagementPathPrefix() string {
+	ingress := cluster.ManagementIngress()
+	if ingress == nil {
+		return ""
+	}
+	return strings.TrimRight(ingress.PathPrefix, "/")
+}
+
+// CanaryRollout returns true if changes of the Pod template are rolled out in steps after health checks
+func (cluster *RabbitmqCluster) CanaryRollout() bool {
+	return cluster.Spec.Rollout != nil && cluster.Spec.Rollout.Mode == CanaryRolloutMode
+}
+
 func (cluster *RabbitmqCluster) DisableNonTLSListeners() bool {
 	return cluster.Spec.TLS.DisableNonTLSListeners
 }
@@ -135,6 +135,17 @@ var _ = Describe("RabbitmqCluster", func() {
 				Expect(apierrors.IsInvalid(k8sClient.Create(context.TODO(), invalidService))).To(BeTrue())
 				Expect(k8sClient.Create(context.TODO(), invalidService)).To(MatchError(ContainSubstring("supported values: \"ClusterIP\", \"LoadBalancer\", \"NodePort\"")))
 			})
+
+			By("checking the names of spec.services", func() {
+				for _, name := range []string{"nodes", "server-0", "server-12"} {
+					reservedName := generateRabbitmqClusterObject("rabbit-reserved-" + name)
+					reservedName.Spec.Services = []RabbitmqClusterNamedServiceSpec{{Name: name}}
+					Expect(apierrors.IsInvalid(k8sClient.Create(context.TODO(), reservedName))).To(BeTrue())
+				}
+				allowed := generateRabbitmqClusterObject("rabbit-services")
+				allowed.Spec.Services = []RabbitmqClusterNamedServiceSpec{{Name: "server-internal"}, {Name: "nodes-public"}}
+				Expect(k8sClient.Create(context.TODO(), allowed)).To(Succeed())
+			})
 		})
 
 		Describe("ChildResourceName", func() {
@@ -18,10 +18,27 @@ import (
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommunityPlugin) DeepCopyInto(out *CommunityPlugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommunityPlugin.
+func (in *CommunityPlugin) DeepCopy() *CommunityPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(CommunityPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmbeddedLabelsAnnotations) DeepCopyInto(out *EmbeddedLabelsAnnotations) {
 	*out = *in
@@ -80,6 +97,134 @@ func (in *EmbeddedObjectMeta) DeepCopy() *EmbeddedObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureFlagsSpec) DeepCopyInto(out *FeatureFlagsSpec) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = make([]FeatureFlag, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureFlagsSpec.
+func (in *FeatureFlagsSpec) DeepCopy() *FeatureFlagsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureFlagsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementGatewayReference) DeepCopyInto(out *ManagementGatewayReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementGatewayReference.
+func (in *ManagementGatewayReference) DeepCopy() *ManagementGatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementGatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementIngressSpec) DeepCopyInto(out *ManagementIngressSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(ManagementGatewayReference)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ManagementIngressTLSSpec)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementIngressSpec.
+func (in *ManagementIngressSpec) DeepCopy() *ManagementIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementIngressTLSSpec) DeepCopyInto(out *ManagementIngressTLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementIngressTLSSpec.
+func (in *ManagementIngressTLSSpec) DeepCopy() *ManagementIngressTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementIngressTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistenceVolume) DeepCopyInto(out *PersistenceVolume) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistenceVolume.
+func (in *PersistenceVolume) DeepCopy() *PersistenceVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistenceVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaim) DeepCopyInto(out *PersistentVolumeClaim) {
 	*out = *in
@@ -98,6 +243,31 @@ func (in *PersistentVolumeClaim) DeepCopy() *PersistentVolumeClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudget) DeepCopyInto(out *PodDisruptionBudget) {
+	*out = *in
+	if in.EmbeddedLabelsAnnotations != nil {
+		in, out := &in.EmbeddedLabelsAnnotations, &out.EmbeddedLabelsAnnotations
+		*out = new(EmbeddedLabelsAnnotations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = new(policyv1beta1.PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudget.
+func (in *PodDisruptionBudget) DeepCopy() *PodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodTemplateSpec) DeepCopyInto(out *PodTemplateSpec) {
 	*out = *in
@@ -158,6 +328,23 @@ func (in *RabbitmqClusterConfigurationSpec) DeepCopyInto(out *RabbitmqClusterCon
 		*out = make([]Plugin, len(*in))
 		copy(*out, *in)
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = new(FeatureFlagsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommunityPlugins != nil {
+		in, out := &in.CommunityPlugins, &out.CommunityPlugins
+		*out = make([]CommunityPlugin, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterConfigurationSpec.
@@ -195,6 +382,51 @@ func (in *RabbitmqClusterDefaultUser) DeepCopy() *RabbitmqClusterDefaultUser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterEndpoint) DeepCopyInto(out *RabbitmqClusterEndpoint) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterEndpoint.
+func (in *RabbitmqClusterEndpoint) DeepCopy() *RabbitmqClusterEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterFeatureFlagsStatus) DeepCopyInto(out *RabbitmqClusterFeatureFlagsStatus) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Disabled != nil {
+		in, out := &in.Disabled, &out.Disabled
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterFeatureFlagsStatus.
+func (in *RabbitmqClusterFeatureFlagsStatus) DeepCopy() *RabbitmqClusterFeatureFlagsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterFeatureFlagsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterList) DeepCopyInto(out *RabbitmqClusterList) {
 	*out = *in
@@ -227,6 +459,167 @@ func (in *RabbitmqClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterManagementSpec) DeepCopyInto(out *RabbitmqClusterManagementSpec) {
+	*out = *in
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(ManagementIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterManagementSpec.
+func (in *RabbitmqClusterManagementSpec) DeepCopy() *RabbitmqClusterManagementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterManagementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterMonitoringSpec) DeepCopyInto(out *RabbitmqClusterMonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterMonitoringSpec.
+func (in *RabbitmqClusterMonitoringSpec) DeepCopy() *RabbitmqClusterMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNamedServiceSpec) DeepCopyInto(out *RabbitmqClusterNamedServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]Listener, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNamedServiceSpec.
+func (in *RabbitmqClusterNamedServiceSpec) DeepCopy() *RabbitmqClusterNamedServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNamedServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNamedServiceStatus) DeepCopyInto(out *RabbitmqClusterNamedServiceStatus) {
+	*out = *in
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNamedServiceStatus.
+func (in *RabbitmqClusterNamedServiceStatus) DeepCopy() *RabbitmqClusterNamedServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNamedServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNetworkPolicySpec) DeepCopyInto(out *RabbitmqClusterNetworkPolicySpec) {
+	*out = *in
+	if in.ClientPeers != nil {
+		in, out := &in.ClientPeers, &out.ClientPeers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNetworkPolicySpec.
+func (in *RabbitmqClusterNetworkPolicySpec) DeepCopy() *RabbitmqClusterNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNodeRecoveryStatus) DeepCopyInto(out *RabbitmqClusterNodeRecoveryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNodeRecoveryStatus.
+func (in *RabbitmqClusterNodeRecoveryStatus) DeepCopy() *RabbitmqClusterNodeRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNodeRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNodeStatus) DeepCopyInto(out *RabbitmqClusterNodeStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNodeStatus.
+func (in *RabbitmqClusterNodeStatus) DeepCopy() *RabbitmqClusterNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterNodeZone) DeepCopyInto(out *RabbitmqClusterNodeZone) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterNodeZone.
+func (in *RabbitmqClusterNodeZone) DeepCopy() *RabbitmqClusterNodeZone {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterNodeZone)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterOverrideSpec) DeepCopyInto(out *RabbitmqClusterOverrideSpec) {
 	*out = *in
@@ -240,6 +633,11 @@ func (in *RabbitmqClusterOverrideSpec) DeepCopyInto(out *RabbitmqClusterOverride
 		*out = new(Service)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterOverrideSpec.
@@ -252,6 +650,28 @@ func (in *RabbitmqClusterOverrideSpec) DeepCopy() *RabbitmqClusterOverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterPerPodServiceSpec) DeepCopyInto(out *RabbitmqClusterPerPodServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPerPodServiceSpec.
+func (in *RabbitmqClusterPerPodServiceSpec) DeepCopy() *RabbitmqClusterPerPodServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterPerPodServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterPersistenceSpec) DeepCopyInto(out *RabbitmqClusterPersistenceSpec) {
 	*out = *in
@@ -265,6 +685,13 @@ func (in *RabbitmqClusterPersistenceSpec) DeepCopyInto(out *RabbitmqClusterPersi
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]PersistenceVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterPersistenceSpec.
@@ -277,6 +704,26 @@ func (in *RabbitmqClusterPersistenceSpec) DeepCopy() *RabbitmqClusterPersistence
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterRolloutSpec) DeepCopyInto(out *RabbitmqClusterRolloutSpec) {
+	*out = *in
+	if in.StepSize != nil {
+		in, out := &in.StepSize, &out.StepSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterRolloutSpec.
+func (in *RabbitmqClusterRolloutSpec) DeepCopy() *RabbitmqClusterRolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterRolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterSecretReference) DeepCopyInto(out *RabbitmqClusterSecretReference) {
 	*out = *in
@@ -299,6 +746,31 @@ func (in *RabbitmqClusterSecretReference) DeepCopy() *RabbitmqClusterSecretRefer
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterSecuritySpec) DeepCopyInto(out *RabbitmqClusterSecuritySpec) {
+	*out = *in
+	if in.RunAsUser != nil {
+		in, out := &in.RunAsUser, &out.RunAsUser
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RunAsGroup != nil {
+		in, out := &in.RunAsGroup, &out.RunAsGroup
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterSecuritySpec.
+func (in *RabbitmqClusterSecuritySpec) DeepCopy() *RabbitmqClusterSecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterSecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RabbitmqClusterServiceReference) DeepCopyInto(out *RabbitmqClusterServiceReference) {
 	*out = *in
@@ -376,6 +848,49 @@ func (in *RabbitmqClusterSpec) DeepCopyInto(out *RabbitmqClusterSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(RabbitmqClusterNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Monitoring.DeepCopyInto(&out.Monitoring)
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = new(RabbitmqClusterTopologySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PerPodService != nil {
+		in, out := &in.PerPodService, &out.PerPodService
+		*out = new(RabbitmqClusterPerPodServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Management != nil {
+		in, out := &in.Management, &out.Management
+		*out = new(RabbitmqClusterManagementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]RabbitmqClusterNamedServiceSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(RabbitmqClusterSecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RabbitmqClusterRolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterSpec.
@@ -408,6 +923,70 @@ func (in *RabbitmqClusterStatus) DeepCopyInto(out *RabbitmqClusterStatus) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.DeferredActions != nil {
+		in, out := &in.DeferredActions, &out.DeferredActions
+		*out = make([]DeferredAction, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainedNodes != nil {
+		in, out := &in.DrainedNodes, &out.DrainedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]RabbitmqClusterEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FeatureFlags != nil {
+		in, out := &in.FeatureFlags, &out.FeatureFlags
+		*out = new(RabbitmqClusterFeatureFlagsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]RabbitmqClusterNamedServiceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]RabbitmqClusterNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodesRefreshedAt != nil {
+		in, out := &in.NodesRefreshedAt, &out.NodesRefreshedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.NodeRecovery != nil {
+		in, out := &in.NodeRecovery, &out.NodeRecovery
+		*out = new(RabbitmqClusterNodeRecoveryStatus)
+		**out = **in
+	}
+	if in.PersistenceExpansionPendingRestart != nil {
+		in, out := &in.PersistenceExpansionPendingRestart, &out.PersistenceExpansionPendingRestart
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageClassMigration != nil {
+		in, out := &in.StorageClassMigration, &out.StorageClassMigration
+		*out = new(RabbitmqClusterStorageClassMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]RabbitmqClusterNodeZone, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterStatus.
@@ -420,6 +999,56 @@ func (in *RabbitmqClusterStatus) DeepCopy() *RabbitmqClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterStorageClassMigrationStatus) DeepCopyInto(out *RabbitmqClusterStorageClassMigrationStatus) {
+	*out = *in
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigratedPods != nil {
+		in, out := &in.MigratedPods, &out.MigratedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterStorageClassMigrationStatus.
+func (in *RabbitmqClusterStorageClassMigrationStatus) DeepCopy() *RabbitmqClusterStorageClassMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterStorageClassMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RabbitmqClusterTopologySpec) DeepCopyInto(out *RabbitmqClusterTopologySpec) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = new(TopologySpreadSpec)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = new(TopologySpreadSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RabbitmqClusterTopologySpec.
+func (in *RabbitmqClusterTopologySpec) DeepCopy() *RabbitmqClusterTopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RabbitmqClusterTopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Service) DeepCopyInto(out *Service) {
 	*out = *in
@@ -526,3 +1155,18 @@ func (in *TLSSpec) DeepCopy() *TLSSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpreadSpec) DeepCopyInto(out *TopologySpreadSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpreadSpec.
+func (in *TopologySpreadSpec) DeepCopy() *TopologySpreadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpreadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
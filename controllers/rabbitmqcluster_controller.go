@@ -23,6 +23,7 @@ import (
 	"github.com/rabbitmq/cluster-operator/internal/resource"
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
@@ -30,9 +31,12 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	clientretry "k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +44,8 @@ import (
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -63,12 +69,25 @@ type RabbitmqClusterReconciler struct {
 	ClusterConfig *rest.Config
 	Clientset     *kubernetes.Clientset
 	PodExecutor   PodExecutor
+
+	// Maximum number of RabbitmqClusters reconciled concurrently; defaults to 1
+	MaxConcurrentReconciles int
+	// Rate limiter of the work queue; defaults to the controller-runtime default rate limiter
+	RateLimiter ratelimiter.RateLimiter
+	// Image of RabbitMQ nodes if not set in the RabbitmqCluster
+	DefaultRabbitmqImage string
+	// Rewrites applied to the images of all Pods
+	ImageRegistryRewrites []resource.ImageRegistryRewrite
+	// Image pull secrets added to all Pods
+	DefaultImagePullSecrets []corev1.LocalObjectReference
+	// Interval at which status.nodes is refreshed; status.nodes is not published if zero
+	NodesStatusRefreshInterval time.Duration
 }
 
 // the rbac rule requires an empty row at the end to render
 // +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
-// +kubebuilder:rbac:groups="",resources=pods,verbs=update;get;list;watch
-// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=update;get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;watch;list
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
@@ -78,9 +97,17 @@ type RabbitmqClusterReconciler struct {
 // +kubebuilder:rbac:groups=rabbitmq.com,resources=rabbitmqclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;create;patch
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update
-// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;create;update;delete
 
 func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := ctrl.LoggerFrom(ctx)
@@ -122,6 +149,10 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	if err := r.validateMaintenanceWindows(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if requeueAfter, err := r.updateStatus(ctx, rabbitmqCluster); err != nil || requeueAfter > 0 {
 		return ctrl.Result{RequeueAfter: requeueAfter}, err
 	}
@@ -145,9 +176,22 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	logger.Info("Start reconciling",
 		"spec", string(instanceSpec))
 
+	gatewayAPIInstalled, err := r.gatewayAPIInstalled()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	prometheusOperatorInstalled, err := r.prometheusOperatorInstalled()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	resourceBuilder := resource.RabbitmqResourceBuilder{
-		Instance: rabbitmqCluster,
-		Scheme:   r.Scheme,
+		Instance:                    r.withDefaultImage(rabbitmqCluster),
+		Scheme:                      r.Scheme,
+		ImageRegistryRewrites:       r.ImageRegistryRewrites,
+		DefaultImagePullSecrets:     r.DefaultImagePullSecrets,
+		GatewayAPIInstalled:         gatewayAPIInstalled,
+		PrometheusOperatorInstalled: prometheusOperatorInstalled,
 	}
 
 	builders, err := resourceBuilder.ResourceBuilders()
@@ -155,7 +199,20 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
+	// updates of the StatefulSet templates are held back outside maintenance windows
+	windowOpen, _, err := maintenanceWindowOpen(rabbitmqCluster, time.Now())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	for _, builder := range builders {
+		if optional, ok := builder.(resource.OptionalResourceBuilder); ok && !optional.Needed() {
+			if err := r.deleteIfExists(ctx, logger, rabbitmqCluster, builder); err != nil {
+				return ctrl.Result{}, err
+			}
+			continue
+		}
+
 		resource, err := builder.Build()
 		if err != nil {
 			return ctrl.Result{}, err
@@ -170,34 +227,57 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 				return ctrl.Result{}, err
 			}
 
-			// only checks for PVC expansion and scale down if statefulSet is created
-			// else continue to CreateOrUpdate()
-			if !k8serrors.IsNotFound(err) {
-				if err := builder.Update(sts); err != nil {
-					return ctrl.Result{}, err
-				}
-				if err = r.reconcilePVC(ctx, rabbitmqCluster, current, sts); err != nil {
-					rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "FailedReconcilePVC", err.Error())
-					if statusErr := r.Status().Update(ctx, rabbitmqCluster); statusErr != nil {
-						logger.Error(statusErr, "Failed to update ReconcileSuccess condition state")
-					}
-					return ctrl.Result{}, err
-				}
-				if r.scaleDown(ctx, rabbitmqCluster, current, sts) {
-					// return when cluster scale down detected; unsupported operation
-					return ctrl.Result{}, nil
+			if k8serrors.IsNotFound(err) {
+				current = nil
+			} else if err := builder.Update(sts); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// PVC expansion may be in progress even if the statefulSet does not exist
+			requeueAfter, err := r.reconcilePVC(ctx, rabbitmqCluster, current, sts)
+			if err != nil {
+				rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "FailedReconcilePVC", err.Error())
+				if statusErr := r.Status().Update(ctx, rabbitmqCluster); statusErr != nil {
+					logger.Error(statusErr, "Failed to update ReconcileSuccess condition state")
 				}
+				return ctrl.Result{}, err
+			}
+			if requeueAfter > 0 {
+				return ctrl.Result{RequeueAfter: requeueAfter}, nil
+			}
+
+			// only checks for scale down if statefulSet is created
+			// else continue to CreateOrUpdate()
+			if current != nil && r.scaleDown(ctx, rabbitmqCluster, current, sts) {
+				// return when cluster scale down detected; unsupported operation
+				return ctrl.Result{}, nil
 			}
 		}
 
 		var operationResult controllerutil.OperationResult
+		var previous client.Object
+		var stsUpdateDeferred, canaryStarted bool
 		err = clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
 			var apiError error
 			operationResult, apiError = controllerutil.CreateOrUpdate(ctx, r.Client, resource, func() error {
-				return builder.Update(resource)
+				previous = resource.DeepCopyObject().(client.Object)
+				if err := builder.Update(resource); err != nil {
+					return err
+				}
+				var holdErr error
+				if stsUpdateDeferred, holdErr = holdStatefulSetUpdate(previous, resource, windowOpen); holdErr != nil {
+					return holdErr
+				}
+				canaryStarted = !stsUpdateDeferred && startCanaryRollout(rabbitmqCluster, previous, resource)
+				return nil
 			})
 			return apiError
 		})
+		if meta.IsNoMatchError(err) {
+			// optional resources of CRDs which are not installed, e.g. Prometheus Operator monitors
+			logger.Info("skipping resource of a kind which is not installed", "name", resource.GetName(), "kind", resource.GetObjectKind().GroupVersionKind().Kind)
+			continue
+		}
 		r.logAndRecordOperationResult(logger, rabbitmqCluster, resource, operationResult, err)
 		if err != nil {
 			rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "Error", err.Error())
@@ -207,9 +287,21 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			return ctrl.Result{}, err
 		}
 
-		if err = r.annotateIfNeeded(ctx, logger, builder, operationResult, rabbitmqCluster); err != nil {
+		if err = r.annotateIfNeeded(ctx, logger, builder, operationResult, rabbitmqCluster, previous, resource); err != nil {
 			return ctrl.Result{}, err
 		}
+
+		if builder.UpdateMayRequireStsRecreate() {
+			if err := r.setDeferredAction(ctx, rabbitmqCluster, rabbitmqv1beta1.DeferredStatefulSetUpdate, stsUpdateDeferred); err != nil {
+				return ctrl.Result{}, err
+			}
+			if canaryStarted {
+				r.Recorder.Event(rabbitmqCluster, corev1.EventTypeNormal, "RolloutStarted", "updating the canary Pod")
+				if err := r.setRolloutCondition(ctx, rabbitmqCluster, corev1.ConditionFalse, "RolloutStarted", "updating the canary Pod"); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
 	}
 
 	if requeueAfter, err := r.restartStatefulSetIfNeeded(ctx, logger, rabbitmqCluster); err != nil || requeueAfter > 0 {
@@ -222,6 +314,60 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err := r.setBinding(ctx, rabbitmqCluster); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.reconcileBindingCA(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setImage(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setZones(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setManagementURL(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setEndpoints(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setPendingRestart(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileNamedServices(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setNodesStatus(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileDrainedNodes(ctx, rabbitmqCluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The StorageClass migration deletes Pods and therefore runs before the post-deploy steps,
+	// which requeue until all Pods are ready.
+	if requeueAfter, err := r.migrateStorageClass(ctx, rabbitmqCluster); err != nil || requeueAfter > 0 {
+		if err != nil {
+			rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "FailedReconcilePVC", err.Error())
+			if writerErr := r.Status().Update(ctx, rabbitmqCluster); writerErr != nil {
+				logger.Error(writerErr, "Failed to update ReconcileSuccess condition state")
+			}
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+
+	// Recovering a node which lost its data deletes its Pod as well.
+	if requeueAfter, err := r.recoverLostNode(ctx, rabbitmqCluster); err != nil || requeueAfter > 0 {
+		if err != nil {
+			rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "FailedNodeRecovery", err.Error())
+			if writerErr := r.Status().Update(ctx, rabbitmqCluster); writerErr != nil {
+				logger.Error(writerErr, "Failed to update ReconcileSuccess condition state")
+			}
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+
+	if requeueAfter, err := r.reconcileRollout(ctx, rabbitmqCluster); err != nil || requeueAfter > 0 {
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
 
 	// By this point the StatefulSet may have finished deploying. Run any
 	// post-deploy steps if so, or requeue until the deployment is finished.
@@ -235,6 +381,10 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{RequeueAfter: requeueAfter}, err
 	}
 
+	if requeueAfter, err := r.awaitPVCResize(ctx, rabbitmqCluster); err != nil || requeueAfter > 0 {
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+
 	// Set ReconcileSuccess to true and update observedGeneration after all reconciliation steps have finished with no error
 	rabbitmqCluster.Status.ObservedGeneration = rabbitmqCluster.GetGeneration()
 	rabbitmqCluster.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionTrue, "Success", "Finish reconciling")
@@ -244,7 +394,8 @@ func (r *RabbitmqClusterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 
 	logger.Info("Finished reconciling")
 
-	return ctrl.Result{}, nil
+	// requeue to refresh status.nodes, or to apply deferred actions once the next maintenance window opens
+	return ctrl.Result{RequeueAfter: untilNextMaintenanceWindow(rabbitmqCluster, r.NodesStatusRefreshInterval)}, nil
 }
 
 // logAndRecordOperationResult - helper function to log and record events with message and error
@@ -275,6 +426,45 @@ func (r *RabbitmqClusterReconciler) logAndRecordOperationResult(logger logr.Logg
 	}
 }
 
+// deleteIfExists deletes child resources which are no longer needed, e.g. an Ingress or a monitor after it is removed from the spec.
+// Resources of the same name which are not controlled by the RabbitmqCluster are left alone.
+func (r *RabbitmqClusterReconciler) deleteIfExists(ctx context.Context, logger logr.Logger, rmq *rabbitmqv1beta1.RabbitmqCluster, builder resource.ResourceBuilder) error {
+	resource, err := builder.Build()
+	if err != nil {
+		return err
+	}
+	// only unstructured resources, e.g. Prometheus Operator monitors, set their kind and may be of a CRD which is not installed
+	if gvk := resource.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		installed, err := r.kindInstalled(gvk)
+		if err != nil || !installed {
+			return err
+		}
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(resource, rmq) {
+		return nil
+	}
+	err = r.Delete(ctx, resource)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		msg := fmt.Sprintf("failed to delete resource %s of Type %T", resource.GetName(), resource)
+		logger.Error(err, msg)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedDelete", msg)
+		return err
+	}
+	msg := fmt.Sprintf("deleted resource %s of Type %T", resource.GetName(), resource)
+	logger.Info(msg)
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulDelete", msg)
+	return nil
+}
+
 func (r *RabbitmqClusterReconciler) updateStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
 	logger := ctrl.LoggerFrom(ctx)
 	childResources, err := r.getChildResources(ctx, rmq)
@@ -284,7 +474,7 @@ func (r *RabbitmqClusterReconciler) updateStatus(ctx context.Context, rmq *rabbi
 
 	oldConditions := make([]status.RabbitmqClusterCondition, len(rmq.Status.Conditions))
 	copy(oldConditions, rmq.Status.Conditions)
-	rmq.Status.SetConditions(childResources)
+	rmq.Status.SetConditions(childResources, resource.ConfigWarnings(rmq)...)
 
 	if !reflect.DeepEqual(rmq.Status.Conditions, oldConditions) {
 		if err = r.Status().Update(ctx, rmq); err != nil {
@@ -345,9 +535,50 @@ func (r *RabbitmqClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.Secret{}).
+		Owns(&policyv1beta1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&networkingv1.Ingress{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.RateLimiter,
+		}).
 		Complete(r)
 }
 
+// gatewayAPIInstalled returns true if the HTTPRoute CRD is installed.
+func (r *RabbitmqClusterReconciler) gatewayAPIInstalled() (bool, error) {
+	return r.kindInstalled(resource.HTTPRouteGVK)
+}
+
+// prometheusOperatorInstalled returns true if the ServiceMonitor CRD is installed.
+func (r *RabbitmqClusterReconciler) prometheusOperatorInstalled() (bool, error) {
+	return r.kindInstalled(resource.ServiceMonitorGVK)
+}
+
+// kindInstalled returns true if the CRD of the kind is installed.
+// The RESTMapper of the manager discovers CRDs installed after the operator started.
+func (r *RabbitmqClusterReconciler) kindInstalled(gvk schema.GroupVersionKind) (bool, error) {
+	_, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to discover kind %s: %w", gvk.Kind, err)
+	}
+	return true, nil
+}
+
+// withDefaultImage returns a copy of the RabbitmqCluster with the default image of the operator
+// if the RabbitmqCluster does not set an image. The default is not persisted in the RabbitmqCluster spec.
+func (r *RabbitmqClusterReconciler) withDefaultImage(rmq *rabbitmqv1beta1.RabbitmqCluster) *rabbitmqv1beta1.RabbitmqCluster {
+	if rmq.Spec.Image != "" {
+		return rmq
+	}
+	withDefault := rmq.DeepCopy()
+	withDefault.Spec.Image = r.DefaultRabbitmqImage
+	return withDefault
+}
+
 func addResourceToIndex(rawObj client.Object) []string {
 	switch resourceObject := rawObj.(type) {
 	case *appsv1.StatefulSet:
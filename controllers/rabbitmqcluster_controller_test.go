@@ -26,6 +26,8 @@ import (
 	"github.com/rabbitmq/cluster-operator/internal/status"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -73,11 +75,20 @@ var _ = Describe("RabbitmqClusterController", func() {
 
 				Expect(sts.Name).To(Equal(cluster.ChildResourceName("server")))
 				Expect(sts.Spec.Template.Spec.ImagePullSecrets).To(BeEmpty())
+				Expect(extractContainer(sts.Spec.Template.Spec.Containers, "rabbitmq").Image).To(Equal(defaultRabbitmqImage))
 
 				Expect(len(sts.Spec.VolumeClaimTemplates)).To(Equal(1))
 				Expect(sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName).To(BeNil())
 			})
 
+			By("reporting the image in status", func() {
+				Eventually(func() string {
+					rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+					Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+					return rmq.Status.Image
+				}, 5).Should(Equal(defaultRabbitmqImage))
+			})
+
 			By("creating the server conf configmap", func() {
 				cfm := configMap(ctx, cluster, "server-conf")
 				Expect(cfm.Name).To(Equal(cluster.ChildResourceName("server-conf")))
@@ -157,6 +168,13 @@ var _ = Describe("RabbitmqClusterController", func() {
 					ContainSubstring("created resource %s of Type *v1.RoleBinding", cluster.ChildResourceName("server")),
 				))
 			})
+
+			By("not creating a PodDisruptionBudget for a single node cluster", func() {
+				Consistently(func() bool {
+					err := client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server"), Namespace: defaultNamespace}, &policyv1beta1.PodDisruptionBudget{})
+					return apierrors.IsNotFound(err)
+				}, 2).Should(BeTrue())
+			})
 		})
 	})
 
@@ -218,6 +236,128 @@ var _ = Describe("RabbitmqClusterController", func() {
 		})
 	})
 
+	Context("PodDisruptionBudget", func() {
+		BeforeEach(func() {
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-pdb",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: pointer.Int32Ptr(3),
+				},
+			}
+
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, cluster)).To(Succeed())
+		})
+
+		It("allows one unavailable Pod of multi node clusters", func() {
+			pdb := &policyv1beta1.PodDisruptionBudget{}
+			Eventually(func() error {
+				return client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server"), Namespace: defaultNamespace}, pdb)
+			}, 5).Should(Succeed())
+			Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": cluster.Name}))
+			Expect(pdb.OwnerReferences[0].Name).To(Equal(cluster.Name))
+		})
+	})
+
+	Context("Management UI Ingress", func() {
+		BeforeEach(func() {
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-management-ingress",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+						Ingress: &rabbitmqv1beta1.ManagementIngressSpec{
+							Host:       "rabbitmq.example.com",
+							PathPrefix: "/rabbitmq",
+						},
+					},
+				},
+			}
+
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, cluster)).To(Succeed())
+		})
+
+		It("creates an Ingress and reports the URL of the management UI", func() {
+			ingress := &networkingv1.Ingress{}
+			Eventually(func() error {
+				return client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("management"), Namespace: defaultNamespace}, ingress)
+			}, 5).Should(Succeed())
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("rabbitmq.example.com"))
+
+			Eventually(func() string {
+				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+				return rmq.Status.ManagementURL
+			}, 5).Should(Equal("http://rabbitmq.example.com/rabbitmq/"))
+		})
+	})
+
+	Context("Endpoints", func() {
+		BeforeEach(func() {
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-endpoints",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Service: rabbitmqv1beta1.RabbitmqClusterServiceSpec{
+						Type: corev1.ServiceTypeLoadBalancer,
+					},
+					Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+						AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_mqtt"},
+					},
+				},
+			}
+
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, cluster)).To(Succeed())
+		})
+
+		It("reports the URLs of the enabled listeners", func() {
+			endpoints := func() []rabbitmqv1beta1.RabbitmqClusterEndpoint {
+				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+				return rmq.Status.Endpoints
+			}
+			Eventually(endpoints, 5).Should(ConsistOf(
+				rabbitmqv1beta1.RabbitmqClusterEndpoint{Listener: "amqp", Internal: "amqp://rabbitmq-endpoints.default.svc:5672"},
+				rabbitmqv1beta1.RabbitmqClusterEndpoint{Listener: "management", Internal: "http://rabbitmq-endpoints.default.svc:15672/"},
+				rabbitmqv1beta1.RabbitmqClusterEndpoint{Listener: "mqtt", Internal: "mqtt://rabbitmq-endpoints.default.svc:1883"},
+				rabbitmqv1beta1.RabbitmqClusterEndpoint{Listener: "prometheus", Internal: "http://rabbitmq-endpoints.default.svc:15692/metrics"},
+			))
+
+			service := &corev1.Service{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName(""), Namespace: defaultNamespace}, service)).To(Succeed())
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+			Expect(client.Status().Update(ctx, service)).To(Succeed())
+
+			Eventually(endpoints, 5).Should(ContainElement(rabbitmqv1beta1.RabbitmqClusterEndpoint{
+				Listener: "amqp",
+				Internal: "amqp://rabbitmq-endpoints.default.svc:5672",
+				External: []string{"amqp://203.0.113.10:5672"},
+			}))
+		})
+	})
+
 	Context("Affinity configurations", func() {
 		var affinity = &corev1.Affinity{
 			PodAffinity: &corev1.PodAffinity{
@@ -21,7 +21,7 @@ func (r *RabbitmqClusterReconciler) runRabbitmqCLICommandsIfAnnotated(ctx contex
 	if err != nil {
 		return 0, err
 	}
-	if !allReplicasReadyAndUpdated(sts) {
+	if !allReplicasReadyOrDrainedAndUpdated(sts, rmq) {
 		logger.Info("not all replicas ready yet; requeuing request to run RabbitMQ CLI commands")
 		return 15 * time.Second, nil
 	}
@@ -48,18 +48,48 @@ func (r *RabbitmqClusterReconciler) runRabbitmqCLICommandsIfAnnotated(ctx contex
 		}
 	}
 
-	// If RabbitMQ cluster is newly created, enable all feature flags since some are disabled by default
-	if sts.ObjectMeta.Annotations != nil && sts.ObjectMeta.Annotations[stsCreateAnnotation] != "" {
+	serverConf, err := r.configMap(ctx, rmq, rmq.ChildResourceName(resource.ServerConfigMapName))
+	if client.IgnoreNotFound(err) != nil {
+		return 0, err
+	}
+	if err == nil && serverConf.Annotations[runtimeConfAnnotation] != "" {
+		if err := r.runSetRuntimeConfigCommands(ctx, rmq, serverConf); err != nil {
+			return 0, err
+		}
+	}
+
+	if rmq.Spec.Rabbitmq.FeatureFlags != nil {
+		// Feature flags are managed declaratively; the one-off enablement on cluster creation is not needed
+		if err := r.reconcileFeatureFlags(ctx, rmq); err != nil {
+			return 0, err
+		}
+		if sts.ObjectMeta.Annotations[stsCreateAnnotation] != "" {
+			if err := r.deleteAnnotation(ctx, sts, stsCreateAnnotation); err != nil {
+				return 0, err
+			}
+		}
+	} else if sts.ObjectMeta.Annotations != nil && sts.ObjectMeta.Annotations[stsCreateAnnotation] != "" {
+		// If RabbitMQ cluster is newly created, enable all feature flags since some are disabled by default
 		if err := r.runEnableFeatureFlagsCommand(ctx, rmq, sts); err != nil {
 			return 0, err
 		}
 	}
 
-	// If the cluster has been marked as needing it, run rabbitmq-queues rebalance all
+	if requeueAfter, err := r.reconcileStreamAdvertisedAddresses(ctx, rmq); err != nil || requeueAfter > 0 {
+		return requeueAfter, err
+	}
+
+	// If the cluster has been marked as needing it, run rabbitmq-queues rebalance all during a maintenance window
 	if rmq.ObjectMeta.Annotations != nil && rmq.ObjectMeta.Annotations[queueRebalanceAnnotation] != "" {
-		if err := r.runQueueRebalanceCommand(ctx, rmq); err != nil {
+		deferred, err := r.deferDisruptiveAction(ctx, rmq, rabbitmqv1beta1.DeferredQueueRebalance)
+		if err != nil {
 			return 0, err
 		}
+		if !deferred {
+			if err := r.runQueueRebalanceCommand(ctx, rmq); err != nil {
+				return 0, err
+			}
+		}
 	}
 
 	return 0, nil
@@ -86,7 +116,7 @@ func (r *RabbitmqClusterReconciler) runEnableFeatureFlagsCommand(ctx context.Con
 // This method implements the 2nd path.
 func (r *RabbitmqClusterReconciler) runSetPluginsCommand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, configMap *corev1.ConfigMap) error {
 	logger := ctrl.LoggerFrom(ctx)
-	plugins := resource.NewRabbitmqPlugins(rmq.Spec.Rabbitmq.AdditionalPlugins)
+	plugins := resource.NewRabbitmqPlugins(rmq.Spec.Rabbitmq.AdditionalPlugins, rmq.Spec.Rabbitmq.CommunityPlugins)
 	for i := int32(0); i < *rmq.Spec.Replicas; i++ {
 		podName := fmt.Sprintf("%s-%d", rmq.ChildResourceName("server"), i)
 		cmd := fmt.Sprintf("rabbitmq-plugins set %s", plugins.AsString(" "))
@@ -126,6 +156,11 @@ func allReplicasReadyAndUpdated(sts *appsv1.StatefulSet) bool {
 	return sts.Status.ReadyReplicas == *sts.Spec.Replicas && !statefulSetBeingUpdated(sts)
 }
 
+// drained nodes are not ready since their client listeners are suspended
+func allReplicasReadyOrDrainedAndUpdated(sts *appsv1.StatefulSet, rmq *rabbitmqv1beta1.RabbitmqCluster) bool {
+	return sts.Status.ReadyReplicas+int32(len(rmq.Status.DrainedNodes)) >= *sts.Spec.Replicas && !statefulSetBeingUpdated(sts)
+}
+
 func statefulSetBeingUpdated(sts *appsv1.StatefulSet) bool {
 	return sts.Status.CurrentRevision != sts.Status.UpdateRevision
 }
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Nodes are put into maintenance mode through this annotation. On the RabbitmqCluster, the value is a comma separated
+// list of Pod names. On a Pod of the RabbitmqCluster, the value "true" drains the node of the Pod.
+const drainAnnotation = "rabbitmq.com/drain"
+
+// reconcileDrainedNodes runs 'rabbitmq-upgrade drain' on the nodes requested to be drained, and 'rabbitmq-upgrade revive'
+// on drained nodes which are not requested anymore. RabbitMQ revives a node when it restarts; such a node is drained again
+// once it reports that it is not under maintenance. The drained nodes are published in status.drainedNodes.
+func (r *RabbitmqClusterReconciler) reconcileDrainedNodes(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	requested, err := r.annotatedPods(ctx, rmq, drainAnnotation)
+	if err != nil {
+		return err
+	}
+	if len(requested) == 0 && len(rmq.Status.DrainedNodes) == 0 {
+		return nil
+	}
+
+	var drained []string
+	for _, podName := range requested {
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return err
+		}
+		if pod == nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if containsString(rmq.Status.DrainedNodes, podName) && !r.nodeRevived(ctx, rmq, podName) {
+			drained = append(drained, podName)
+			continue
+		}
+		if err := r.runMaintenanceCommand(ctx, rmq, podName, "drain"); err != nil {
+			return err
+		}
+		drained = append(drained, podName)
+	}
+
+	for _, podName := range rmq.Status.DrainedNodes {
+		if containsString(requested, podName) {
+			continue
+		}
+		// nodes of deleted or restarting Pods are revived when they start
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return err
+		}
+		if pod == nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if err := r.runMaintenanceCommand(ctx, rmq, podName, "revive"); err != nil {
+			return err
+		}
+	}
+
+	if !reflect.DeepEqual(rmq.Status.DrainedNodes, drained) {
+		rmq.Status.DrainedNodes = drained
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotatedPods returns the sorted names of the Pods listed in the given annotation on the RabbitmqCluster,
+// or whose own annotation is set to "true". Names which do not belong to a Pod of the RabbitmqCluster are ignored.
+func (r *RabbitmqClusterReconciler) annotatedPods(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, annotation string) ([]string, error) {
+	annotated := map[string]bool{}
+	for _, name := range strings.Split(rmq.Annotations[annotation], ",") {
+		annotated[strings.TrimSpace(name)] = true
+	}
+
+	var requested []string
+	for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+		podName := serverPodName(rmq, i)
+		if annotated[podName] {
+			requested = append(requested, podName)
+			continue
+		}
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil && pod.Annotations[annotation] == "true" {
+			requested = append(requested, podName)
+		}
+	}
+	sort.Strings(requested)
+	return requested, nil
+}
+
+func (r *RabbitmqClusterReconciler) runMaintenanceCommand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName, command string) error {
+	logger := ctrl.LoggerFrom(ctx)
+	cmd := "rabbitmq-upgrade " + command
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd)
+	if err != nil {
+		msg := fmt.Sprintf("failed to %s node on pod", command)
+		logger.Error(err, msg, "pod", podName, "command", cmd, "stdout", stdout, "stderr", stderr)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", fmt.Sprintf("%s %s", msg, podName))
+		return fmt.Errorf("%s %s: %v", msg, podName, err)
+	}
+	msg := fmt.Sprintf("successfully ran %s on pod %s", cmd, podName)
+	logger.Info(msg)
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulMaintenance", msg)
+	return nil
+}
+
+// nodeRevived returns true if the node of the Pod reports that it is not under maintenance, e.g. after it restarted.
+// It returns false if the maintenance state of the node cannot be retrieved.
+func (r *RabbitmqClusterReconciler) nodeRevived(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) bool {
+	status := r.clusterStatus(ctx, rmq, podName)
+	if status == nil {
+		return false
+	}
+	return status.MaintenanceStatus[rabbitmqNodeName(rmq, podName)] == "not under maintenance"
+}
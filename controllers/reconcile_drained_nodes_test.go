@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Drained nodes", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		pods             []*corev1.Pod
+		defaultNamespace = "default"
+		drainCommand     = command{"sh", "-c", "rabbitmq-upgrade drain"}
+		reviveCommand    = command{"sh", "-c", "rabbitmq-upgrade revive"}
+		statusCommand    = command{"sh", "-c", "rabbitmq-diagnostics -q cluster_status --formatter json"}
+	)
+
+	getCluster := func() *rabbitmqv1beta1.RabbitmqCluster {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		ExpectWithOffset(1, client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq
+	}
+
+	noWarningsReason := func() string {
+		for _, condition := range getCluster().Status.Conditions {
+			if condition.Type == status.NoWarnings {
+				return condition.Reason
+			}
+		}
+		return ""
+	}
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-drained-nodes",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(2),
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+
+		// envtest does not run the StatefulSet controller
+		pods = nil
+		for i := 0; i < 2; i++ {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cluster.ChildResourceName("server") + []string{"-0", "-1"}[i],
+					Namespace: defaultNamespace,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+				},
+			}
+			Expect(client.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodRunning
+			Expect(client.Status().Update(ctx, pod)).To(Succeed())
+			pods = append(pods, pod)
+		}
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		for _, pod := range pods {
+			Expect(client.Delete(ctx, pod)).To(Succeed())
+		}
+	})
+
+	It("drains and revives the nodes annotated on the RabbitmqCluster", func() {
+		By("draining the node", func() {
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Annotations = map[string]string{"rabbitmq.com/drain": pods[1].Name}
+			})).To(Succeed())
+
+			Eventually(func() []string {
+				return getCluster().Status.DrainedNodes
+			}, 5).Should(Equal([]string{pods[1].Name}))
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(drainCommand))
+			Eventually(noWarningsReason, 5).Should(Equal("NodesDrained"))
+		})
+
+		By("keeping the node drained without draining it again", func() {
+			fakeExecutor.ResetExecutedCommands()
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Labels = map[string]string{"trigger": "reconcile"}
+			})).To(Succeed())
+			Consistently(func() []command {
+				return fakeExecutor.ExecutedCommands()
+			}, 2).ShouldNot(ContainElement(drainCommand))
+			Expect(getCluster().Status.DrainedNodes).To(Equal([]string{pods[1].Name}))
+		})
+
+		By("draining the node again once it is revived by a restart", func() {
+			node1 := "rabbit@" + pods[1].Name + "." + cluster.ChildResourceName("nodes") + "." + defaultNamespace
+			fakeExecutor.SetStdout(statusCommand, `{"maintenance_status": {"`+node1+`": "not under maintenance"}}`)
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Labels = map[string]string{"trigger": "restart"}
+			})).To(Succeed())
+			Eventually(func() []command {
+				return fakeExecutor.ExecutedCommands()
+			}, 5).Should(ContainElement(drainCommand))
+			Expect(getCluster().Status.DrainedNodes).To(Equal([]string{pods[1].Name}))
+			fakeExecutor.ResetStdout()
+		})
+
+		By("reviving the node once the annotation is removed", func() {
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Annotations = map[string]string{}
+			})).To(Succeed())
+
+			Eventually(func() []string {
+				return getCluster().Status.DrainedNodes
+			}, 5).Should(BeEmpty())
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(reviveCommand))
+			Eventually(noWarningsReason, 5).ShouldNot(Equal("NodesDrained"))
+		})
+	})
+
+	It("drains the node of an annotated Pod", func() {
+		pods[0].Annotations = map[string]string{"rabbitmq.com/drain": "true"}
+		Expect(client.Update(ctx, pods[0])).To(Succeed())
+		// trigger a reconcile
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Labels = map[string]string{"trigger": "reconcile"}
+		})).To(Succeed())
+
+		Eventually(func() []string {
+			return getCluster().Status.DrainedNodes
+		}, 5).Should(Equal([]string{pods[0].Name}))
+		Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(drainCommand))
+	})
+})
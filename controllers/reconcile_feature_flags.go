@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const listFeatureFlagsCommand = "rabbitmqctl -s list_feature_flags name state stability"
+
+type featureFlag struct {
+	name      string
+	state     string
+	stability string
+}
+
+func (f featureFlag) enabled() bool {
+	return f.state == "enabled"
+}
+
+// reconcileFeatureFlags enables all feature flags desired by spec.rabbitmq.featureFlags which are not enabled yet,
+// and publishes the enabled and disabled feature flags in the RabbitmqCluster status.
+// Feature flags are cluster-wide, hence it is sufficient to run the commands on a single pod.
+func (r *RabbitmqClusterReconciler) reconcileFeatureFlags(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	logger := ctrl.LoggerFrom(ctx)
+	podName := fmt.Sprintf("%s-0", rmq.ChildResourceName("server"))
+
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", listFeatureFlagsCommand)
+	if err != nil {
+		msg := "failed to list feature flags on pod"
+		logger.Error(err, msg, "pod", podName, "command", listFeatureFlagsCommand, "stdout", stdout, "stderr", stderr)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", fmt.Sprintf("%s %s", msg, podName))
+		return fmt.Errorf("%s %s: %v", msg, podName, err)
+	}
+	featureFlags := parseFeatureFlags(stdout)
+
+	toEnable, unknown := featureFlagsToEnable(rmq.Spec.Rabbitmq.FeatureFlags, featureFlags)
+	if len(unknown) > 0 {
+		msg := fmt.Sprintf("feature flags not supported by the RabbitMQ nodes: %s", strings.Join(unknown, ", "))
+		logger.Info(msg)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "UnknownFeatureFlags", msg)
+	}
+
+	for _, i := range toEnable {
+		cmd := fmt.Sprintf("rabbitmqctl enable_feature_flag %s", featureFlags[i].name)
+		stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd)
+		if err != nil {
+			msg := "failed to enable feature flag on pod"
+			logger.Error(err, msg, "pod", podName, "command", cmd, "stdout", stdout, "stderr", stderr)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", fmt.Sprintf("%s %s", msg, podName))
+			return fmt.Errorf("%s %s: %v", msg, podName, err)
+		}
+		featureFlags[i].state = "enabled"
+		logger.Info("successfully enabled feature flag", "featureFlag", featureFlags[i].name)
+	}
+
+	featureFlagsStatus := &rabbitmqv1beta1.RabbitmqClusterFeatureFlagsStatus{}
+	for _, f := range featureFlags {
+		if f.enabled() {
+			featureFlagsStatus.Enabled = append(featureFlagsStatus.Enabled, f.name)
+		} else {
+			featureFlagsStatus.Disabled = append(featureFlagsStatus.Disabled, f.name)
+		}
+	}
+
+	if !reflect.DeepEqual(rmq.Status.FeatureFlags, featureFlagsStatus) {
+		rmq.Status.FeatureFlags = featureFlagsStatus
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFeatureFlags parses the tab separated output of 'rabbitmqctl -s list_feature_flags name state stability'.
+// The returned feature flags are sorted by name.
+func parseFeatureFlags(output string) []featureFlag {
+	var featureFlags []featureFlag
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		featureFlags = append(featureFlags, featureFlag{name: fields[0], state: fields[1], stability: fields[2]})
+	}
+	sort.Slice(featureFlags, func(i, j int) bool {
+		return featureFlags[i].name < featureFlags[j].name
+	})
+	return featureFlags
+}
+
+// featureFlagsToEnable returns the indices of the disabled feature flags which should be enabled according to the spec,
+// and the names of explicitly requested feature flags the RabbitMQ nodes do not know about.
+func featureFlagsToEnable(spec *rabbitmqv1beta1.FeatureFlagsSpec, featureFlags []featureFlag) ([]int, []string) {
+	var toEnable []int
+	var unknown []string
+
+	switch spec.Mode {
+	case rabbitmqv1beta1.FeatureFlagsModeNone:
+		return nil, nil
+	case rabbitmqv1beta1.FeatureFlagsModeExplicit:
+		for _, desired := range spec.Enable {
+			found := false
+			for i, f := range featureFlags {
+				if f.name == string(desired) {
+					found = true
+					if !f.enabled() && !containsIndex(toEnable, i) {
+						toEnable = append(toEnable, i)
+					}
+					break
+				}
+			}
+			if !found {
+				unknown = append(unknown, string(desired))
+			}
+		}
+	default:
+		for i, f := range featureFlags {
+			if !f.enabled() && f.stability == "stable" {
+				toEnable = append(toEnable, i)
+			}
+		}
+	}
+	return toEnable, unknown
+}
+
+func containsIndex(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
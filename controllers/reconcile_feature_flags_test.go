@@ -0,0 +1,155 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Reconcile feature flags", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+		listCommand      = command{"sh", "-c", "rabbitmqctl -s list_feature_flags name state stability"}
+		featureFlags     = "drop_unroutable_metric\tenabled\tstable\n" +
+			"implicit_default_bindings\tdisabled\tstable\n" +
+			"quorum_queue\tdisabled\tstable\n" +
+			"stream_queue\tdisabled\tstable\n" +
+			"experimental_flag\tdisabled\texperimental\n"
+	)
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
+
+	markStatefulSetReady := func() {
+		sts := statefulSet(ctx, cluster)
+		sts.Status.Replicas = 1
+		sts.Status.ReadyReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+	}
+
+	featureFlagsStatus := func() *rabbitmqv1beta1.RabbitmqClusterFeatureFlagsStatus {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq.Status.FeatureFlags
+	}
+
+	When("mode is set to all", func() {
+		BeforeEach(func() {
+			fakeExecutor.SetStdout(listCommand, featureFlags)
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-feature-flags-all",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+						FeatureFlags: &rabbitmqv1beta1.FeatureFlagsSpec{Mode: rabbitmqv1beta1.FeatureFlagsModeAll},
+					},
+				},
+			}
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		It("enables all disabled stable feature flags and reports them in status", func() {
+			markStatefulSetReady()
+			Eventually(featureFlagsStatus, 5).ShouldNot(BeNil())
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElements(
+				command{"sh", "-c", "rabbitmqctl enable_feature_flag implicit_default_bindings"},
+				command{"sh", "-c", "rabbitmqctl enable_feature_flag quorum_queue"},
+				command{"sh", "-c", "rabbitmqctl enable_feature_flag stream_queue"},
+			))
+			Expect(fakeExecutor.ExecutedCommands()).NotTo(ContainElement(command{"sh", "-c", "rabbitmqctl enable_feature_flag experimental_flag"}))
+			Expect(fakeExecutor.ExecutedCommands()).NotTo(ContainElement(command{"sh", "-c", "rabbitmqctl enable_feature_flag drop_unroutable_metric"}))
+
+			ffStatus := featureFlagsStatus()
+			Expect(ffStatus.Enabled).To(ConsistOf("drop_unroutable_metric", "implicit_default_bindings", "quorum_queue", "stream_queue"))
+			Expect(ffStatus.Disabled).To(ConsistOf("experimental_flag"))
+		})
+
+		It("removes the createdAt annotation without running the one-off command", func() {
+			markStatefulSetReady()
+			Eventually(func() map[string]string {
+				sts := &appsv1.StatefulSet{}
+				Expect(client.Get(ctx, types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.ChildResourceName("server")}, sts)).To(Succeed())
+				return sts.ObjectMeta.Annotations
+			}, 5).ShouldNot(HaveKey("rabbitmq.com/createdAt"))
+			Expect(fakeExecutor.ExecutedCommands()).NotTo(ContainElement(command{"bash", "-c",
+				"set -eo pipefail; rabbitmqctl -s list_feature_flags name state stability | (grep 'disabled\\sstable$' || true) | cut -f 1 | xargs -r -n1 rabbitmqctl enable_feature_flag"}))
+		})
+	})
+
+	When("mode is set to explicit", func() {
+		BeforeEach(func() {
+			fakeExecutor.SetStdout(listCommand, featureFlags)
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-feature-flags-explicit",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+						FeatureFlags: &rabbitmqv1beta1.FeatureFlagsSpec{
+							Mode:   rabbitmqv1beta1.FeatureFlagsModeExplicit,
+							Enable: []rabbitmqv1beta1.FeatureFlag{"quorum_queue", "not_a_feature_flag"},
+						},
+					},
+				},
+			}
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		It("only enables the listed feature flags", func() {
+			markStatefulSetReady()
+			Eventually(featureFlagsStatus, 5).ShouldNot(BeNil())
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(command{"sh", "-c", "rabbitmqctl enable_feature_flag quorum_queue"}))
+			Expect(fakeExecutor.ExecutedCommands()).NotTo(ContainElement(command{"sh", "-c", "rabbitmqctl enable_feature_flag stream_queue"}))
+
+			ffStatus := featureFlagsStatus()
+			Expect(ffStatus.Enabled).To(ConsistOf("drop_unroutable_metric", "quorum_queue"))
+			Expect(ffStatus.Disabled).To(ConsistOf("implicit_default_bindings", "stream_queue", "experimental_flag"))
+		})
+
+		It("publishes a warning event for unknown feature flags", func() {
+			markStatefulSetReady()
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, cluster, "UnknownFeatureFlags")
+			}, 5).Should(ContainSubstring("not_a_feature_flag"))
+		})
+	})
+
+	When("mode is set to none", func() {
+		BeforeEach(func() {
+			fakeExecutor.SetStdout(listCommand, featureFlags)
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-feature-flags-none",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+						FeatureFlags: &rabbitmqv1beta1.FeatureFlagsSpec{Mode: rabbitmqv1beta1.FeatureFlagsModeNone},
+					},
+				},
+			}
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		It("does not enable any feature flag but still reports them in status", func() {
+			markStatefulSetReady()
+			Eventually(featureFlagsStatus, 5).ShouldNot(BeNil())
+			for _, cmd := range fakeExecutor.ExecutedCommands() {
+				Expect(cmd[len(cmd)-1]).NotTo(HavePrefix("rabbitmqctl enable_feature_flag"))
+			}
+			Expect(featureFlagsStatus().Disabled).To(ConsistOf("implicit_default_bindings", "quorum_queue", "stream_queue", "experimental_flag"))
+		})
+	})
+})
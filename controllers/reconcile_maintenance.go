@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/cron"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Disruptive actions are applied immediately while this annotation is set to "true" on the RabbitmqCluster
+	forceMaintenanceAnnotation = "rabbitmq.com/forceMaintenance"
+	// Hash of the Pod template and volume claim templates last applied to the StatefulSet
+	podTemplateHashAnnotation = "rabbitmq.com/podTemplateHash"
+)
+
+// maintenanceWindowOpen returns true if disruptive actions can be applied at the given time,
+// i.e. if no maintenance window is configured, if the force annotation is set, or if a window is open.
+// Otherwise, it returns the start of the next window, which is zero if no schedule activates anymore.
+func maintenanceWindowOpen(rmq *rabbitmqv1beta1.RabbitmqCluster, now time.Time) (bool, time.Time, error) {
+	if len(rmq.Spec.MaintenanceWindows) == 0 || rmq.Annotations[forceMaintenanceAnnotation] == "true" {
+		return true, time.Time{}, nil
+	}
+	var next time.Time
+	for _, window := range rmq.Spec.MaintenanceWindows {
+		schedule, err := cron.Parse(window.Schedule)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid spec.maintenanceWindows: %w", err)
+		}
+		// the window is open if the schedule activated within the last duration
+		start := schedule.Next(now.Add(-window.Duration.Duration))
+		if start.IsZero() {
+			continue
+		}
+		if !start.After(now) {
+			return true, time.Time{}, nil
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return false, next, nil
+}
+
+// validateMaintenanceWindows fails the reconcile if a schedule in spec.maintenanceWindows cannot be parsed,
+// since disruptive actions could otherwise be applied at any time.
+func (r *RabbitmqClusterReconciler) validateMaintenanceWindows(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	if _, _, err := maintenanceWindowOpen(rmq, time.Now()); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to parse maintenance windows")
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", err.Error())
+		rmq.Status.SetCondition(status.ReconcileSuccess, corev1.ConditionFalse, "InvalidMaintenanceWindows", err.Error())
+		if writerErr := r.Status().Update(ctx, rmq); writerErr != nil {
+			ctrl.LoggerFrom(ctx).Error(writerErr, "Failed to update ReconcileSuccess condition state")
+		}
+		return err
+	}
+	return nil
+}
+
+// deferDisruptiveAction returns true if the given action must wait for the next maintenance window.
+// The action is listed in status.deferredActions until it is applied.
+func (r *RabbitmqClusterReconciler) deferDisruptiveAction(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, action rabbitmqv1beta1.DeferredAction) (bool, error) {
+	open, _, err := maintenanceWindowOpen(rmq, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return !open, r.setDeferredAction(ctx, rmq, action, !open)
+}
+
+// setDeferredAction adds the action to or removes it from status.deferredActions
+// and keeps status.nextMaintenanceWindow up to date.
+func (r *RabbitmqClusterReconciler) setDeferredAction(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, action rabbitmqv1beta1.DeferredAction, deferred bool) error {
+	logger := ctrl.LoggerFrom(ctx)
+	var actions []rabbitmqv1beta1.DeferredAction
+	for _, a := range rmq.Status.DeferredActions {
+		if a != action {
+			actions = append(actions, a)
+		}
+	}
+	if deferred {
+		actions = append(actions, action)
+		sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	}
+
+	var nextWindow *metav1.Time
+	if len(actions) > 0 {
+		if _, next, err := maintenanceWindowOpen(rmq, time.Now()); err == nil && !next.IsZero() {
+			nextWindow = &metav1.Time{Time: next}
+		}
+	}
+
+	if reflect.DeepEqual(rmq.Status.DeferredActions, actions) && nextWindow.Equal(rmq.Status.NextMaintenanceWindow) {
+		return nil
+	}
+	if deferred && !containsDeferredAction(rmq.Status.DeferredActions, action) {
+		msg := fmt.Sprintf("deferring %s until the next maintenance window", action)
+		logger.Info(msg, "nextMaintenanceWindow", nextWindow)
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "DeferredToMaintenanceWindow", msg)
+	}
+	rmq.Status.DeferredActions = actions
+	rmq.Status.NextMaintenanceWindow = nextWindow
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		logger.Error(err, "failed to update deferred actions", "action", action)
+		return err
+	}
+	return nil
+}
+
+// untilNextMaintenanceWindow returns the given requeue duration, shortened to the start of the next
+// maintenance window if actions are deferred
+func untilNextMaintenanceWindow(rmq *rabbitmqv1beta1.RabbitmqCluster, requeueAfter time.Duration) time.Duration {
+	if len(rmq.Status.DeferredActions) == 0 || rmq.Status.NextMaintenanceWindow == nil {
+		return requeueAfter
+	}
+	untilWindow := time.Until(rmq.Status.NextMaintenanceWindow.Time) + time.Second
+	if untilWindow < time.Second {
+		untilWindow = time.Second
+	}
+	if requeueAfter == 0 || untilWindow < requeueAfter {
+		return untilWindow
+	}
+	return requeueAfter
+}
+
+// holdStatefulSetUpdate is called after the StatefulSet builder updated the StatefulSet.
+// Outside maintenance windows, it restores the previous Pod template and volume claim templates of an existing StatefulSet,
+// so that no rolling update is triggered. Changes are detected by comparing a hash of the desired templates with the hash
+// of the templates applied last, since the API server adds defaults to the applied templates.
+// StatefulSets without the hash annotation, e.g. created by a previous version of the operator, are compared semantically instead.
+// It returns true if an update of the templates is deferred.
+func holdStatefulSetUpdate(previous, desired client.Object, windowOpen bool) (bool, error) {
+	sts, ok := desired.(*appsv1.StatefulSet)
+	if !ok {
+		return false, nil
+	}
+	hash, err := podTemplateHash(sts)
+	if err != nil {
+		return false, err
+	}
+	if sts.Annotations == nil {
+		sts.Annotations = map[string]string{}
+	}
+	// the StatefulSet is being created if it does not have a resource version
+	if windowOpen || previous.GetResourceVersion() == "" || previous.GetAnnotations()[podTemplateHashAnnotation] == hash {
+		sts.Annotations[podTemplateHashAnnotation] = hash
+		return false, nil
+	}
+
+	current := previous.(*appsv1.StatefulSet)
+	if _, ok := current.Annotations[podTemplateHashAnnotation]; !ok && templatesUnchanged(current, sts) {
+		sts.Annotations[podTemplateHashAnnotation] = hash
+		return false, nil
+	}
+
+	sts.Spec.Template = current.Spec.Template
+	sts.Spec.VolumeClaimTemplates = current.Spec.VolumeClaimTemplates
+	if previousHash, ok := current.Annotations[podTemplateHashAnnotation]; ok {
+		sts.Annotations[podTemplateHashAnnotation] = previousHash
+	} else {
+		delete(sts.Annotations, podTemplateHashAnnotation)
+	}
+	return true, nil
+}
+
+// templatesUnchanged returns true if all fields set in the desired templates equal the current templates,
+// which may contain additional fields defaulted by the API server
+func templatesUnchanged(current, desired *appsv1.StatefulSet) bool {
+	template := desired.Spec.Template.DeepCopy()
+	delete(template.Annotations, stsRestartAnnotation)
+	return equality.Semantic.DeepDerivative(*template, current.Spec.Template) &&
+		equality.Semantic.DeepDerivative(desired.Spec.VolumeClaimTemplates, current.Spec.VolumeClaimTemplates)
+}
+
+// podTemplateHash ignores the restart annotation, which is set on the Pod template by restartStatefulSetIfNeeded
+func podTemplateHash(sts *appsv1.StatefulSet) (string, error) {
+	template := sts.Spec.Template.DeepCopy()
+	delete(template.Annotations, stsRestartAnnotation)
+	data, err := json.Marshal(struct {
+		Template             *corev1.PodTemplateSpec        `json:"template"`
+		VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates"`
+	}{template, sts.Spec.VolumeClaimTemplates})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash the Pod template: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
+func containsDeferredAction(actions []rabbitmqv1beta1.DeferredAction, action rabbitmqv1beta1.DeferredAction) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
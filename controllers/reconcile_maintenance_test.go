@@ -0,0 +1,140 @@
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Maintenance windows", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+	)
+
+	getCluster := func() *rabbitmqv1beta1.RabbitmqCluster {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		ExpectWithOffset(1, client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq
+	}
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
+
+	When("no maintenance window is open", func() {
+		BeforeEach(func() {
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-maintenance-windows",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: pointer.Int32Ptr(1),
+					// only open during the first minute of the year
+					MaintenanceWindows: []rabbitmqv1beta1.MaintenanceWindow{{
+						Schedule: "0 0 1 1 *",
+						Duration: metav1.Duration{Duration: time.Minute},
+					}},
+				},
+			}
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		It("creates the StatefulSet and defers updates of its Pod template until forced", func() {
+			By("creating the StatefulSet immediately", func() {
+				sts := statefulSet(ctx, cluster)
+				Expect(sts.Annotations).To(HaveKey("rabbitmq.com/podTemplateHash"))
+				Expect(getCluster().Status.DeferredActions).To(BeEmpty())
+			})
+
+			By("annotating an unchanged StatefulSet without a template hash instead of deferring", func() {
+				// StatefulSets created by previous versions of the operator are not annotated
+				sts := statefulSet(ctx, cluster)
+				delete(sts.Annotations, "rabbitmq.com/podTemplateHash")
+				Expect(client.Update(ctx, sts)).To(Succeed())
+
+				Eventually(func() map[string]string {
+					return statefulSet(ctx, cluster).Annotations
+				}, 5).Should(HaveKey("rabbitmq.com/podTemplateHash"))
+				Consistently(func() []rabbitmqv1beta1.DeferredAction {
+					return getCluster().Status.DeferredActions
+				}, 3, 1).Should(BeEmpty())
+			})
+
+			By("keeping the current image and reporting the deferred update", func() {
+				Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+					r.Spec.Image = "rabbitmq:deferred"
+				})).To(Succeed())
+
+				Eventually(func() []rabbitmqv1beta1.DeferredAction {
+					return getCluster().Status.DeferredActions
+				}, 5).Should(ConsistOf(rabbitmqv1beta1.DeferredStatefulSetUpdate))
+				Expect(statefulSet(ctx, cluster).Spec.Template.Spec.Containers[0].Image).NotTo(Equal("rabbitmq:deferred"))
+
+				nextWindow := getCluster().Status.NextMaintenanceWindow
+				Expect(nextWindow).NotTo(BeNil())
+				Expect(nextWindow.Time.UTC().Month()).To(Equal(time.January))
+				Expect(nextWindow.Time.UTC().Day()).To(Equal(1))
+			})
+
+			By("applying the update once the force annotation is set", func() {
+				Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+					r.Annotations = map[string]string{"rabbitmq.com/forceMaintenance": "true"}
+				})).To(Succeed())
+
+				Eventually(func() string {
+					return statefulSet(ctx, cluster).Spec.Template.Spec.Containers[0].Image
+				}, 5).Should(Equal("rabbitmq:deferred"))
+				Eventually(func() []rabbitmqv1beta1.DeferredAction {
+					return getCluster().Status.DeferredActions
+				}, 5).Should(BeEmpty())
+				Expect(getCluster().Status.NextMaintenanceWindow).To(BeNil())
+			})
+		})
+	})
+
+	When("a maintenance window has an invalid schedule", func() {
+		BeforeEach(func() {
+			cluster = &rabbitmqv1beta1.RabbitmqCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rabbitmq-invalid-maintenance-windows",
+					Namespace: defaultNamespace,
+				},
+				Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+					Replicas: pointer.Int32Ptr(1),
+					MaintenanceWindows: []rabbitmqv1beta1.MaintenanceWindow{{
+						Schedule: "0 25 * * *",
+						Duration: metav1.Duration{Duration: time.Hour},
+					}},
+				},
+			}
+			Expect(client.Create(ctx, cluster)).To(Succeed())
+			waitForClusterCreation(ctx, cluster, client)
+		})
+
+		It("sets ReconcileSuccess to false", func() {
+			Eventually(func() *status.RabbitmqClusterCondition {
+				for _, condition := range getCluster().Status.Conditions {
+					if condition.Type == status.ReconcileSuccess {
+						return &condition
+					}
+				}
+				return nil
+			}, 5).Should(And(
+				Not(BeNil()),
+				WithTransform(func(c *status.RabbitmqClusterCondition) corev1.ConditionStatus { return c.Status }, Equal(corev1.ConditionFalse)),
+				WithTransform(func(c *status.RabbitmqClusterCondition) string { return c.Reason }, Equal("InvalidMaintenanceWindows")),
+			))
+		})
+	})
+})
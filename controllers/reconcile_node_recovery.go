@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Pods whose RabbitMQ nodes lost their data are recovered through this annotation. On the RabbitmqCluster, the value is
+// a comma separated list of Pod names. On a Pod of the RabbitmqCluster, the value "true" recovers the node of the Pod.
+// The annotation is removed once the recovery starts.
+const lostNodeDataAnnotation = "rabbitmq.com/lostNodeData"
+
+// A node whose persistent volume was lost comes back with an empty data directory under its old node name.
+// It does not join the cluster, which still lists it as a member. The recovery reuses the steps of a StorageClass migration
+// and is persisted in the status of the RabbitmqCluster, so that an interrupted recovery picks up where it left off:
+//  1. a node is detected as reset, or its Pod is listed in the annotation; the recovery starts with the Forgetting stage
+//  2. Forgetting: the Pod is removed from the annotation, the node is stopped and reset, a peer removes it from the cluster,
+//     and the Pod is deleted
+//  3. Rejoining: the StatefulSet recreates the Pod and the fresh node joins the cluster through peer discovery;
+//     once the Pod is ready, quorum queue and stream replicas are added to the node
+//  4. Syncing: the recovery completes once the quorum queue and stream replicas on the node have caught up
+//
+// recoverLostNode moves the recovery forward. A non-zero duration is returned while the recovery is in progress.
+func (r *RabbitmqClusterReconciler) recoverLostNode(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	recovery := rmq.Status.NodeRecovery
+
+	if recovery == nil {
+		// the node of a single replica RabbitmqCluster has no peer to recover from
+		if *rmq.Spec.Replicas < 2 || rmq.Status.StorageClassMigration != nil {
+			return 0, nil
+		}
+		podName, err := r.lostNode(ctx, rmq)
+		if err != nil || podName == "" {
+			return 0, err
+		}
+		msg := fmt.Sprintf("recovering RabbitMQ node of pod %s which lost its data", podName)
+		logger.Info(msg)
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "NodeRecovery", msg)
+		return time.Second, r.updateNodeRecovery(ctx, rmq, &rabbitmqv1beta1.RabbitmqClusterNodeRecoveryStatus{
+			Pod:   podName,
+			Stage: rabbitmqv1beta1.NodeRecoveryForgetting,
+		})
+	}
+
+	podName := recovery.Pod
+	node := rabbitmqNodeName(rmq, podName)
+
+	switch recovery.Stage {
+	case rabbitmqv1beta1.NodeRecoveryForgetting:
+		// the annotation is only removed once the recovery is persisted, so that the request is not lost
+		if err := r.removeLostNodeDataAnnotation(ctx, rmq, podName); err != nil {
+			return 0, err
+		}
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return 0, err
+		}
+		if pod != nil && pod.DeletionTimestamp.IsZero() && pod.Status.Phase == corev1.PodRunning {
+			if err := r.runNodeRecoveryCommand(ctx, rmq, podName, "rabbitmqctl stop_app && rabbitmqctl reset"); err != nil {
+				return 0, err
+			}
+		}
+		if err := r.runNodeRecoveryCommand(ctx, rmq, peerPodName(rmq, podName), forgetClusterNodeCommand(node)); err != nil {
+			return 0, err
+		}
+		// the reset node joins the cluster through peer discovery when it starts
+		if pod != nil {
+			if err := r.deleteMigratedObject(ctx, rmq, pod); err != nil {
+				return 0, err
+			}
+		}
+		logger.Info("removed RabbitMQ node from the cluster and deleted Pod", "pod", podName)
+		recovery.Stage = rabbitmqv1beta1.NodeRecoveryRejoining
+		return 5 * time.Second, r.updateNodeRecovery(ctx, rmq, recovery)
+
+	case rabbitmqv1beta1.NodeRecoveryRejoining:
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return 0, err
+		}
+		if pod == nil || !pod.DeletionTimestamp.IsZero() || !podReady(pod) {
+			logger.Info("waiting for Pod to rejoin the cluster", "pod", podName)
+			return 10 * time.Second, nil
+		}
+		if err := r.runNodeRecoveryCommand(ctx, rmq, podName, growReplicasCommand(node)); err != nil {
+			return 0, err
+		}
+		logger.Info("added quorum queue and stream replicas to RabbitMQ node", "pod", podName)
+		recovery.Stage = rabbitmqv1beta1.NodeRecoverySyncing
+		return time.Second, r.updateNodeRecovery(ctx, rmq, recovery)
+
+	case rabbitmqv1beta1.NodeRecoverySyncing:
+		if !r.replicasSynchronised(ctx, rmq, podName) {
+			return 10 * time.Second, nil
+		}
+		msg := fmt.Sprintf("recovered RabbitMQ node of pod %s", podName)
+		logger.Info(msg)
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulNodeRecovery", msg)
+		return 0, r.updateNodeRecovery(ctx, rmq, nil)
+	}
+	return 0, nil
+}
+
+// lostNode returns the first Pod listed in the annotation, or the first Pod whose node came back reset:
+// the Pod is ready, the node only knows about itself, and another node still lists it as a member.
+// Nodes are only checked if status.nodes reports the node of a ready Pod as not running, which is the case for reset nodes.
+func (r *RabbitmqClusterReconciler) lostNode(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (string, error) {
+	annotated, err := r.annotatedPods(ctx, rmq, lostNodeDataAnnotation)
+	if err != nil {
+		return "", err
+	}
+	if len(annotated) > 0 {
+		return annotated[0], nil
+	}
+
+	suspicious := false
+	for _, node := range rmq.Status.Nodes {
+		if node.Ready && node.State == rabbitmqv1beta1.NodeStateNotRunning {
+			suspicious = true
+		}
+	}
+	if !suspicious {
+		return "", nil
+	}
+
+	statuses := map[string]*clusterStatus{}
+	for _, node := range rmq.Status.Nodes {
+		if node.Ready {
+			if status := r.clusterStatus(ctx, rmq, node.Pod); status != nil {
+				statuses[node.Node] = status
+			}
+		}
+	}
+	for _, node := range rmq.Status.Nodes {
+		status, ok := statuses[node.Node]
+		if !ok || len(status.DiskNodes) != 1 || status.DiskNodes[0] != node.Node {
+			continue
+		}
+		for peer, peerStatus := range statuses {
+			if peer != node.Node && containsString(peerStatus.DiskNodes, node.Node) {
+				return node.Pod, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// removeLostNodeDataAnnotation removes the Pod from the annotation on the RabbitmqCluster and removes the annotation from the Pod
+func (r *RabbitmqClusterReconciler) removeLostNodeDataAnnotation(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) error {
+	pod, err := r.pod(ctx, rmq, podName)
+	if err != nil {
+		return err
+	}
+	if pod != nil && pod.Annotations[lostNodeDataAnnotation] != "" {
+		if err := r.deleteAnnotation(ctx, pod, lostNodeDataAnnotation); err != nil {
+			return err
+		}
+	}
+
+	value, ok := rmq.Annotations[lostNodeDataAnnotation]
+	if !ok {
+		return nil
+	}
+	listed := false
+	var remaining []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == podName {
+			listed = true
+		} else if name != "" {
+			remaining = append(remaining, name)
+		}
+	}
+	if !listed {
+		return nil
+	}
+	if len(remaining) == 0 {
+		return r.deleteAnnotation(ctx, rmq, lostNodeDataAnnotation)
+	}
+	return r.updateAnnotation(ctx, rmq, rmq.Namespace, rmq.Name, lostNodeDataAnnotation, strings.Join(remaining, ","))
+}
+
+func (r *RabbitmqClusterReconciler) updateNodeRecovery(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, recovery *rabbitmqv1beta1.RabbitmqClusterNodeRecoveryStatus) error {
+	rmq.Status.NodeRecovery = recovery
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update node recovery status")
+		return err
+	}
+	return nil
+}
+
+func (r *RabbitmqClusterReconciler) runNodeRecoveryCommand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName, cmd string) error {
+	return r.runPersistenceCommand(ctx, rmq, podName, cmd, "failed to recover node on pod")
+}
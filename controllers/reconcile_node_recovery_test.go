@@ -0,0 +1,123 @@
+package controllers_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Node recovery", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+		lostNode         = "rabbit@rabbitmq-node-recovery-server-1.rabbitmq-node-recovery-nodes.default"
+	)
+
+	getCluster := func() *rabbitmqv1beta1.RabbitmqCluster {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		ExpectWithOffset(1, client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq
+	}
+
+	recoveryStage := func() rabbitmqv1beta1.NodeRecoveryStage {
+		if recovery := getCluster().Status.NodeRecovery; recovery != nil {
+			return recovery.Stage
+		}
+		return ""
+	}
+
+	// envtest does not run the StatefulSet controller
+	createReadyPod := func(podIndex string) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.ChildResourceName("server") + "-" + podIndex,
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+			},
+		}
+		Expect(client.Create(ctx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(client.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-node-recovery",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(2),
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+		createReadyPod("0")
+		createReadyPod("1")
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		for _, podIndex := range []string{"0", "1"} {
+			Expect(runtimeClient.IgnoreNotFound(client.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.ChildResourceName("server") + "-" + podIndex,
+				Namespace: defaultNamespace,
+			}}))).To(Succeed())
+		}
+	})
+
+	It("forgets the node annotated on the RabbitmqCluster and grows replicas back onto it once it rejoins", func() {
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Annotations = map[string]string{"rabbitmq.com/lostNodeData": cluster.ChildResourceName("server") + "-1"}
+		})).To(Succeed())
+
+		By("resetting the node, forgetting it from a peer and deleting its Pod", func() {
+			Eventually(recoveryStage, 5).Should(Equal(rabbitmqv1beta1.NodeRecoveryRejoining))
+			Expect(getCluster().Status.NodeRecovery.Pod).To(Equal(cluster.ChildResourceName("server") + "-1"))
+			Expect(getCluster().Annotations).NotTo(HaveKey("rabbitmq.com/lostNodeData"))
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElements(
+				command{"bash", "-c", "rabbitmqctl stop_app && rabbitmqctl reset"},
+				command{"bash", "-c", "if rabbitmqctl -q cluster_status --formatter json | grep -qF '\"" + lostNode + "\"'; then rabbitmqctl forget_cluster_node " + lostNode + "; fi"},
+			))
+			Eventually(func() error {
+				return client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server") + "-1", Namespace: defaultNamespace}, &corev1.Pod{})
+			}, 5).ShouldNot(Succeed())
+		})
+
+		By("growing quorum queues and streams onto the node once its Pod is ready", func() {
+			createReadyPod("1")
+			// trigger a reconcile
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Labels = map[string]string{"trigger": "reconcile"}
+			})).To(Succeed())
+
+			Eventually(func() *rabbitmqv1beta1.RabbitmqClusterNodeRecoveryStatus {
+				return getCluster().Status.NodeRecovery
+			}, 15).Should(BeNil())
+			// the replicas of the fresh node are compared with their leaders
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(WithTransform(func(c command) string {
+				return strings.Join(c, " ")
+			}, ContainSubstring("rabbitmq-queues -q quorum_status"))))
+			Expect(aggregateEventMsgs(ctx, cluster, "SuccessfulNodeRecovery")).To(
+				ContainSubstring("recovered RabbitMQ node of pod " + cluster.ChildResourceName("server") + "-1"))
+		})
+	})
+
+	It("does not recover nodes unless a node is reported as lost", func() {
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Labels = map[string]string{"trigger": "reconcile"}
+		})).To(Succeed())
+		Consistently(recoveryStage, 2).Should(BeEmpty())
+	})
+})
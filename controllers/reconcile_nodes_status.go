@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const clusterStatusCommand = "rabbitmq-diagnostics -q cluster_status --formatter json"
+
+// clusterStatus is the subset of the output of 'rabbitmq-diagnostics cluster_status --formatter json' used in status.nodes
+type clusterStatus struct {
+	DiskNodes         []string                     `json:"disk_nodes"`
+	RunningNodes      []string                     `json:"running_nodes"`
+	Partitions        map[string][]string          `json:"partitions"`
+	MaintenanceStatus map[string]string            `json:"maintenance_status"`
+	Versions          map[string]map[string]string `json:"versions"`
+}
+
+// setNodesStatus publishes one entry per existing Pod in status.nodes.
+// The RabbitMQ view of the cluster is taken from the first ready Pod; if there is none, or the command fails,
+// the RabbitMQ state of all nodes is Unknown. Failing to query RabbitMQ does not fail the reconcile.
+// RabbitMQ is queried at most once per refresh interval; in between, the Pod and PVC details are updated
+// and the RabbitMQ state and version of each node are kept.
+func (r *RabbitmqClusterReconciler) setNodesStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	var nodes []rabbitmqv1beta1.RabbitmqClusterNodeStatus
+	refreshedAt := rmq.Status.NodesRefreshedAt
+	if r.NodesStatusRefreshInterval > 0 {
+		var err error
+		if nodes, refreshedAt, err = r.nodesStatus(ctx, rmq, time.Now()); err != nil {
+			return err
+		}
+	} else {
+		refreshedAt = nil
+	}
+
+	if !reflect.DeepEqual(rmq.Status.Nodes, nodes) || !reflect.DeepEqual(rmq.Status.NodesRefreshedAt, refreshedAt) {
+		rmq.Status.Nodes = nodes
+		rmq.Status.NodesRefreshedAt = refreshedAt
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodesStatus returns status.nodes and the time RabbitMQ was last queried for the state of the nodes
+func (r *RabbitmqClusterReconciler) nodesStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, now time.Time) ([]rabbitmqv1beta1.RabbitmqClusterNodeStatus, *metav1.Time, error) {
+	var pods []*corev1.Pod
+	var ordinals []int
+	var readyPod string
+	for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: serverPodName(rmq, i)}, pod); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get Pod %s: %w", serverPodName(rmq, i), err)
+		}
+		if readyPod == "" && podReady(pod) {
+			readyPod = pod.Name
+		}
+		pods = append(pods, pod)
+		ordinals = append(ordinals, i)
+	}
+
+	refreshedAt := rmq.Status.NodesRefreshedAt
+	previous := map[string]rabbitmqv1beta1.RabbitmqClusterNodeStatus{}
+	for _, node := range rmq.Status.Nodes {
+		previous[node.Node] = node
+	}
+	var status *clusterStatus
+	refreshDue := refreshedAt == nil || now.Sub(refreshedAt.Time) >= r.NodesStatusRefreshInterval
+	if readyPod != "" && refreshDue {
+		status = r.clusterStatus(ctx, rmq, readyPod)
+		refreshedAt = &metav1.Time{Time: now}
+	}
+
+	var nodes []rabbitmqv1beta1.RabbitmqClusterNodeStatus
+	for i, pod := range pods {
+		node := rabbitmqv1beta1.RabbitmqClusterNodeStatus{
+			Pod:   pod.Name,
+			Node:  rabbitmqNodeName(rmq, pod.Name),
+			Ready: podReady(pod),
+			State: status.nodeState(rabbitmqNodeName(rmq, pod.Name)),
+			Zone:  pod.Labels[resource.ZoneLabel],
+		}
+		if status != nil {
+			node.Version = status.Versions[node.Node]["rabbitmq_version"]
+		} else if previousNode, ok := previous[node.Node]; ok && readyPod != "" && !refreshDue {
+			node.State = previousNode.State
+			node.Version = previousNode.Version
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name == "rabbitmq" {
+				node.RestartCount = c.RestartCount
+			}
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvcName := rmq.PVCName(ordinals[i])
+		err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: pvcName}, pvc)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", pvcName, err)
+		}
+		if err == nil {
+			node.PersistentVolumeClaim = pvc.Name
+			if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+				node.Capacity = &capacity
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, refreshedAt, nil
+}
+
+func (r *RabbitmqClusterReconciler) clusterStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) *clusterStatus {
+	logger := ctrl.LoggerFrom(ctx)
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", clusterStatusCommand)
+	if err != nil {
+		logger.Error(err, "failed to get cluster status on pod", "pod", podName, "command", clusterStatusCommand, "stdout", stdout, "stderr", stderr)
+		return nil
+	}
+	status := &clusterStatus{}
+	if err := json.Unmarshal([]byte(stdout), status); err != nil {
+		logger.Error(err, "failed to parse cluster status", "pod", podName, "stdout", stdout)
+		return nil
+	}
+	return status
+}
+
+// nodeState returns Unknown if the cluster status could not be retrieved
+func (s *clusterStatus) nodeState(node string) rabbitmqv1beta1.NodeState {
+	if s == nil {
+		return rabbitmqv1beta1.NodeStateUnknown
+	}
+	if s.MaintenanceStatus[node] == "under maintenance" {
+		return rabbitmqv1beta1.NodeStateUnderMaintenance
+	}
+	if len(s.Partitions[node]) > 0 {
+		return rabbitmqv1beta1.NodeStatePartitioned
+	}
+	if containsString(s.RunningNodes, node) {
+		return rabbitmqv1beta1.NodeStateRunning
+	}
+	return rabbitmqv1beta1.NodeStateNotRunning
+}
@@ -0,0 +1,152 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Nodes status", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		pods             []*corev1.Pod
+		defaultNamespace = "default"
+		statusCommand    = command{"sh", "-c", "rabbitmq-diagnostics -q cluster_status --formatter json"}
+		node0            = "rabbit@rabbitmq-nodes-status-server-0.rabbitmq-nodes-status-nodes.default"
+		node1            = "rabbit@rabbitmq-nodes-status-server-1.rabbitmq-nodes-status-nodes.default"
+	)
+
+	nodesStatus := func() []rabbitmqv1beta1.RabbitmqClusterNodeStatus {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		ExpectWithOffset(1, client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq.Status.Nodes
+	}
+
+	BeforeEach(func() {
+		// RabbitMQ is queried as soon as a Pod is ready
+		fakeExecutor.SetStdout(statusCommand, `{"running_nodes": ["`+node0+`"], "partitions": {}, `+
+			`"maintenance_status": {"`+node0+`": "not under maintenance"}, `+
+			`"versions": {"`+node0+`": {"rabbitmq_version": "3.8.16", "erlang_version": "24.0"}}}`)
+
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-nodes-status",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(2),
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+
+		// envtest does not run the StatefulSet controller
+		pods = nil
+		for i, ready := range []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse} {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cluster.ChildResourceName("server") + []string{"-0", "-1"}[i],
+					Namespace: defaultNamespace,
+					Labels:    map[string]string{"topology.kubernetes.io/zone": "zone-a"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+				},
+			}
+			Expect(client.Create(ctx, pod)).To(Succeed())
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "rabbitmq", RestartCount: int32(i * 3)}}
+			Expect(client.Status().Update(ctx, pod)).To(Succeed())
+			pods = append(pods, pod)
+		}
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		for _, pod := range pods {
+			Expect(client.Delete(ctx, pod)).To(Succeed())
+		}
+	})
+
+	It("reports the state of each node as seen by RabbitMQ", func() {
+		// trigger a reconcile
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Labels = map[string]string{"trigger": "reconcile"}
+		})).To(Succeed())
+
+		Eventually(nodesStatus, 5).Should(Equal([]rabbitmqv1beta1.RabbitmqClusterNodeStatus{
+			{
+				Pod:     pods[0].Name,
+				Node:    node0,
+				Ready:   true,
+				State:   rabbitmqv1beta1.NodeStateRunning,
+				Version: "3.8.16",
+				Zone:    "zone-a",
+			},
+			{
+				Pod:          pods[1].Name,
+				Node:         node1,
+				State:        rabbitmqv1beta1.NodeStateNotRunning,
+				Zone:         "zone-a",
+				RestartCount: 3,
+			},
+		}))
+	})
+
+	It("queries RabbitMQ at most once per refresh interval", func() {
+		Eventually(nodesStatus, 5).Should(HaveLen(2))
+		Eventually(fakeExecutor.ExecutedCommands, 5).Should(ContainElement(statusCommand))
+
+		fakeExecutor.ResetExecutedCommands()
+		pods[1].Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "rabbitmq", RestartCount: 4}}
+		Expect(client.Status().Update(ctx, pods[1])).To(Succeed())
+		// trigger a reconcile
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Labels = map[string]string{"trigger": "reconcile"}
+		})).To(Succeed())
+
+		Eventually(func() int32 {
+			return nodesStatus()[1].RestartCount
+		}, 5).Should(Equal(int32(4)))
+		Expect(nodesStatus()[0].State).To(Equal(rabbitmqv1beta1.NodeStateRunning))
+		Expect(fakeExecutor.ExecutedCommands()).NotTo(ContainElement(statusCommand))
+	})
+
+	It("reports the PVC of each Pod when a Pod is missing", func() {
+		Expect(client.Delete(ctx, pods[0])).To(Succeed())
+		pods = pods[1:]
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "persistence-" + cluster.ChildResourceName("server") + "-1",
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse("10Gi")},
+				},
+			},
+		}
+		Expect(client.Create(ctx, pvc)).To(Succeed())
+		defer func() { Expect(client.Delete(ctx, pvc)).To(Succeed()) }()
+
+		// trigger a reconcile
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Labels = map[string]string{"trigger": "reconcile"}
+		})).To(Succeed())
+
+		Eventually(func() []string {
+			var claims []string
+			for _, node := range nodesStatus() {
+				claims = append(claims, node.Pod+"="+node.PersistentVolumeClaim)
+			}
+			return claims
+		}, 5).Should(Equal([]string{pods[0].Name + "=" + pvc.Name}))
+	})
+})
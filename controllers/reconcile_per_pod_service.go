@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// streamAdvertisedAddressAnnotation records on each Pod the stream address its RabbitMQ node advertises,
+// suffixed by the start time of the RabbitMQ container since the advertised address does not survive a node restart.
+const streamAdvertisedAddressAnnotation = "rabbitmq.com/streamAdvertisedAddress"
+
+type advertisedAddress struct {
+	host    string
+	port    int32
+	tlsPort int32
+}
+
+// reconcileStreamAdvertisedAddresses sets the advertised host and ports of the stream plugin of each RabbitMQ node
+// to the address of its per Pod Service, so that stream clients outside the Kubernetes cluster can connect to each node.
+// It requeues until the addresses of all Services are known, e.g. until the LoadBalancers got provisioned.
+func (r *RabbitmqClusterReconciler) reconcileStreamAdvertisedAddresses(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
+	if rmq.Spec.PerPodService == nil || !rmq.AdditionalPluginEnabled("rabbitmq_stream") {
+		return 0, nil
+	}
+	logger := ctrl.LoggerFrom(ctx)
+
+	var requeueAfter time.Duration
+	for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+		podName := serverPodName(rmq, i)
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: podName}, pod); err != nil {
+			return 0, fmt.Errorf("failed to get Pod %s: %w", podName, err)
+		}
+		service := &corev1.Service{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: podName}, service); err != nil {
+			return 0, fmt.Errorf("failed to get Service %s: %w", podName, err)
+		}
+
+		address, err := r.perPodServiceAddress(ctx, service, pod)
+		if err != nil {
+			return 0, err
+		}
+		if address.host == "" {
+			logger.Info("address of per Pod Service not known yet; requeuing request to advertise stream address", "service", podName)
+			requeueAfter = 15 * time.Second
+			continue
+		}
+
+		started := rabbitmqContainerStartedAt(pod)
+		if started == "" {
+			requeueAfter = 15 * time.Second
+			continue
+		}
+		value := fmt.Sprintf("%s:%d:%d@%s", address.host, address.port, address.tlsPort, started)
+		if pod.Annotations[streamAdvertisedAddressAnnotation] == value {
+			continue
+		}
+
+		cmd := setStreamAdvertisedAddressCommand(address)
+		stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd)
+		if err != nil {
+			msg := "failed to set stream advertised address on pod"
+			logger.Error(err, msg, "pod", podName, "command", cmd, "stdout", stdout, "stderr", stderr)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", fmt.Sprintf("%s %s", msg, podName))
+			return 0, fmt.Errorf("%s %s: %v", msg, podName, err)
+		}
+		if err := r.updateAnnotation(ctx, &corev1.Pod{}, rmq.Namespace, podName, streamAdvertisedAddressAnnotation, value); err != nil {
+			return 0, err
+		}
+		logger.Info("successfully set stream advertised address", "pod", podName, "host", address.host, "port", address.port, "tlsPort", address.tlsPort)
+	}
+	return requeueAfter, nil
+}
+
+// perPodServiceAddress returns the ingress address of a LoadBalancer Service and its Service ports,
+// or the external (falling back to the internal) IP address of the node of the Pod and the node ports of a NodePort Service.
+// The host is empty if the address is not known yet.
+func (r *RabbitmqClusterReconciler) perPodServiceAddress(ctx context.Context, service *corev1.Service, pod *corev1.Pod) (advertisedAddress, error) {
+	address := advertisedAddress{}
+	nodePort := service.Spec.Type == corev1.ServiceTypeNodePort
+	for _, p := range service.Spec.Ports {
+		port := p.Port
+		if nodePort {
+			port = p.NodePort
+		}
+		switch p.Name {
+		case "stream":
+			address.port = port
+		case "streams":
+			address.tlsPort = port
+		}
+	}
+
+	if !nodePort {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.Hostname != "" {
+				address.host = ingress.Hostname
+				return address, nil
+			}
+			if ingress.IP != "" {
+				address.host = ingress.IP
+				return address, nil
+			}
+		}
+		return address, nil
+	}
+
+	if pod.Spec.NodeName == "" {
+		return address, nil
+	}
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		return address, fmt.Errorf("failed to get node %s of Pod %s: %w", pod.Spec.NodeName, pod.Name, err)
+	}
+	address.host = nodeAddress(node, corev1.NodeExternalIP)
+	if address.host == "" {
+		address.host = nodeAddress(node, corev1.NodeInternalIP)
+	}
+	return address, nil
+}
+
+func nodeAddress(node *corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, a := range node.Status.Addresses {
+		if a.Type == addressType {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+func rabbitmqContainerStartedAt(pod *corev1.Pod) string {
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.Name == "rabbitmq" && c.State.Running != nil {
+			return c.State.Running.StartedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+// setStreamAdvertisedAddressCommand sets the advertised address at runtime, since all nodes share the same configuration file.
+// Ports the Service does not expose are not advertised.
+func setStreamAdvertisedAddressCommand(address advertisedAddress) string {
+	eval := fmt.Sprintf(`application:set_env(rabbitmq_stream, advertised_host, <<"%s">>)`, address.host)
+	if address.port != 0 {
+		eval += fmt.Sprintf(", application:set_env(rabbitmq_stream, advertised_port, %d)", address.port)
+	}
+	if address.tlsPort != 0 {
+		eval += fmt.Sprintf(", application:set_env(rabbitmq_stream, advertised_tls_port, %d)", address.tlsPort)
+	}
+	return fmt.Sprintf("rabbitmqctl eval '%s.'", eval)
+}
@@ -0,0 +1,133 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Per Pod Services", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		pod              *corev1.Pod
+		defaultNamespace = "default"
+	)
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-per-pod-service",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas:      pointer.Int32Ptr(1),
+				PerPodService: &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{},
+				Rabbitmq: rabbitmqv1beta1.RabbitmqClusterConfigurationSpec{
+					AdditionalPlugins: []rabbitmqv1beta1.Plugin{"rabbitmq_stream"},
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		Expect(client.Delete(ctx, pod)).To(Succeed())
+	})
+
+	It("creates a Service per Pod and advertises its address to stream clients", func() {
+		serviceName := cluster.ChildResourceName("server") + "-0"
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return client.Get(ctx, types.NamespacedName{Name: serviceName, Namespace: defaultNamespace}, service)
+		}, 5).Should(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(service.Spec.Selector).To(HaveKeyWithValue("statefulset.kubernetes.io/pod-name", serviceName))
+
+		// envtest does not run the StatefulSet controller and no LoadBalancer controller
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      serviceName,
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+			},
+		}
+		Expect(client.Create(ctx, pod)).To(Succeed())
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "rabbitmq",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+		}}
+		Expect(client.Status().Update(ctx, pod)).To(Succeed())
+
+		service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+		Expect(client.Status().Update(ctx, service)).To(Succeed())
+
+		sts := statefulSet(ctx, cluster)
+		sts.Status.Replicas = 1
+		sts.Status.ReadyReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+		Eventually(func() map[string]string {
+			Expect(client.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: defaultNamespace}, pod)).To(Succeed())
+			return pod.Annotations
+		}, 5).Should(HaveKey("rabbitmq.com/streamAdvertisedAddress"))
+		Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(command{"sh", "-c",
+			`rabbitmqctl eval 'application:set_env(rabbitmq_stream, advertised_host, <<"203.0.113.10">>), application:set_env(rabbitmq_stream, advertised_port, 5552).'`}))
+	})
+})
+
+var _ = Describe("Per Pod Services which are not needed", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		service          *corev1.Service
+		defaultNamespace = "default"
+	)
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-no-per-pod-service",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(1),
+			},
+		}
+		service = &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.ChildResourceName("server") + "-0",
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "amqp", Port: 5672}},
+			},
+		}
+		Expect(client.Create(ctx, service)).To(Succeed())
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		Expect(client.Delete(ctx, service)).To(Succeed())
+	})
+
+	It("does not delete a Service of the same name which is not controlled by the RabbitmqCluster", func() {
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Rabbitmq.AdditionalConfig = "log.console.level = debug"
+		})).To(Succeed())
+
+		Consistently(func() error {
+			return client.Get(ctx, types.NamespacedName{Name: service.Name, Namespace: defaultNamespace}, &corev1.Service{})
+		}, 3, 1).Should(Succeed())
+	})
+})
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/go-logr/logr"
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,151 +22,395 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (r *RabbitmqClusterReconciler) reconcilePVC(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, sts *appsv1.StatefulSet) error {
-	resize, err := r.needsPVCExpand(ctx, rmq, current, sts)
-	if err != nil {
-		return err
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// PVC expansion is a state machine whose current stage is persisted in the status of the RabbitmqCluster,
+// so that an expansion interrupted by an operator restart picks up where it left off:
+//  1. no stage: the desired capacity of a volume claim template is larger than the current capacity;
+//     the StorageClasses are checked to allow volume expansion, the stage is set to DeletingStatefulSet and the StatefulSet is deleted
+//     without deleting its Pods (volume claim templates of a StatefulSet cannot be updated)
+//  2. DeletingStatefulSet: once the StatefulSet is gone, all PVCs are updated to the desired capacity and the stage is set to Resizing;
+//     the StatefulSet is recreated with the new volume claim templates in the same reconcile
+//  3. Resizing: the stage is cleared once the storage capacity of all PVCs is reported as resized;
+//     Pods whose file systems are only resized on restart are reported in status until they are restarted
+//     a further increase of the desired capacity while resizing starts over at stage 1
+//
+// reconcilePVC moves PVC expansion forward. current is nil if the StatefulSet does not exist.
+// A non-zero duration is returned if the StatefulSet must not be created or updated yet.
+func (r *RabbitmqClusterReconciler) reconcilePVC(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) (time.Duration, error) {
+	logger := ctrl.LoggerFrom(ctx)
+
+	if current != nil {
+		if err := r.verifyVolumeClaimTemplatesUnchanged(ctx, rmq, current, desired); err != nil {
+			return 0, err
+		}
 	}
 
-	if resize {
-		if err := r.expandPVC(ctx, rmq, current, sts); err != nil {
-			return err
+	// PVCs are not expanded while they are replaced by a StorageClass migration
+	if requeueAfter, err := r.reconcileStorageClassMigration(ctx, rmq, current, desired); err != nil || requeueAfter > 0 || rmq.Status.StorageClassMigration != nil {
+		return requeueAfter, err
+	}
+
+	switch rmq.Status.PersistenceExpansionStage {
+	case rabbitmqv1beta1.PersistenceExpansionDeletingStatefulSet:
+		if current != nil {
+			if current.DeletionTimestamp.IsZero() {
+				if err := r.deleteSts(ctx, rmq, current); err != nil {
+					return 0, err
+				}
+			}
+			logger.Info("waiting for statefulSet to be deleted before expanding PersistentVolumeClaims", "statefulSet", current.Name)
+			return 2 * time.Second, nil
 		}
+		if err := r.updatePVCs(ctx, rmq, storageCapacities(desired.Spec.VolumeClaimTemplates)); err != nil {
+			return 0, err
+		}
+		return 0, r.setPersistenceExpansionStage(ctx, rmq, rabbitmqv1beta1.PersistenceExpansionResizing)
+	case rabbitmqv1beta1.PersistenceExpansionResizing:
+		// progress of the resize is checked by awaitPVCResize once the StatefulSet has been recreated;
+		// a further increase of the storage capacity restarts the expansion below since volume claim templates are immutable
 	}
-	return nil
+
+	if current == nil {
+		return 0, nil
+	}
+
+	resize, err := r.needsPVCExpand(ctx, rmq, current, desired)
+	if err != nil {
+		return 0, err
+	}
+	if len(resize) == 0 {
+		return 0, r.setDeferredAction(ctx, rmq, rabbitmqv1beta1.DeferredPersistenceExpansion, false)
+	}
+
+	if err := r.verifyVolumeExpansionAllowed(ctx, rmq, current, resize); err != nil {
+		return 0, err
+	}
+
+	// outside maintenance windows, the StatefulSet is updated with its current volume claim templates
+	if deferred, err := r.deferDisruptiveAction(ctx, rmq, rabbitmqv1beta1.DeferredPersistenceExpansion); err != nil || deferred {
+		return 0, err
+	}
+
+	currentCapacities := storageCapacities(current.Spec.VolumeClaimTemplates)
+	for _, name := range sortedTemplateNames(resize) {
+		currentCapacity := currentCapacities[name]
+		desiredCapacity := resize[name]
+		logger.Info(fmt.Sprintf("updating storage capacity of %s from %s to %s", name, currentCapacity.String(), desiredCapacity.String()))
+	}
+
+	if err := r.setPersistenceExpansionStage(ctx, rmq, rabbitmqv1beta1.PersistenceExpansionDeletingStatefulSet); err != nil {
+		return 0, err
+	}
+	if err := r.deleteSts(ctx, rmq, current); err != nil {
+		return 0, err
+	}
+	return 2 * time.Second, nil
 }
 
-func (r *RabbitmqClusterReconciler) expandPVC(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) error {
+// awaitPVCResize clears the PVC expansion stage once all PVCs report their requested storage capacity.
+// Pods whose file systems are only resized on restart are listed in status.persistenceExpansionPendingRestart.
+// A non-zero duration is returned while the resize is in progress.
+func (r *RabbitmqClusterReconciler) awaitPVCResize(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
 	logger := ctrl.LoggerFrom(ctx)
+	if rmq.Status.PersistenceExpansionStage != rabbitmqv1beta1.PersistenceExpansionResizing {
+		return 0, nil
+	}
 
-	currentCapacity, err := persistenceStorageCapacity(current.Spec.VolumeClaimTemplates)
+	sts, err := r.statefulSet(ctx, rmq)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	desiredCapacity, err := persistenceStorageCapacity(desired.Spec.VolumeClaimTemplates)
-	if err != nil {
-		return err
+	resizing := false
+	var pendingRestart []string
+	for _, t := range sts.Spec.VolumeClaimTemplates {
+		for i := 0; i < int(*sts.Spec.Replicas); i++ {
+			PVCName := pvcName(rmq, t.Name, i)
+			PVC := &corev1.PersistentVolumeClaim{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: PVCName}, PVC); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return 0, err
+			}
+			if fileSystemResizePending(PVC) {
+				if podName := serverPodName(rmq, i); !containsString(pendingRestart, podName) {
+					pendingRestart = append(pendingRestart, podName)
+				}
+				continue
+			}
+			if reason := pvcResizePending(PVC); reason != "" {
+				logger.Info("waiting for PersistentVolumeClaim to be resized", "PVC", PVCName, "reason", reason)
+				resizing = true
+			}
+		}
+	}
+	sort.Strings(pendingRestart)
+	if err := r.setPersistenceExpansionPendingRestart(ctx, rmq, pendingRestart); err != nil {
+		return 0, err
+	}
+	if resizing || len(pendingRestart) > 0 {
+		return 10 * time.Second, nil
 	}
 
-	logger.Info(fmt.Sprintf("updating storage capacity from %s to %s", currentCapacity.String(), desiredCapacity.String()))
+	logger.Info("successfully expanded PersistentVolumeClaims")
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulExpansion", "expanded PersistentVolumeClaims")
+	return 0, r.setPersistenceExpansionStage(ctx, rmq, "")
+}
 
-	if err := r.deleteSts(ctx, rmq); err != nil {
+// setPersistenceExpansionPendingRestart records the Pods which need to be restarted to resize their file systems,
+// and emits a warning for each Pod which was not recorded yet
+func (r *RabbitmqClusterReconciler) setPersistenceExpansionPendingRestart(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, pods []string) error {
+	if reflect.DeepEqual(rmq.Status.PersistenceExpansionPendingRestart, pods) {
+		return nil
+	}
+	for _, podName := range pods {
+		if !containsString(rmq.Status.PersistenceExpansionPendingRestart, podName) {
+			msg := fmt.Sprintf("file system of pod %s is only resized when the pod is restarted; delete the pod to complete the expansion", podName)
+			ctrl.LoggerFrom(ctx).Info(msg)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "PersistenceExpansionPendingRestart", msg)
+		}
+	}
+	rmq.Status.PersistenceExpansionPendingRestart = pods
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update pods pending restart for persistence expansion")
 		return err
 	}
+	return nil
+}
 
-	if err := r.updatePVC(ctx, rmq, *current.Spec.Replicas, desiredCapacity); err != nil {
+func (r *RabbitmqClusterReconciler) setPersistenceExpansionStage(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, stage rabbitmqv1beta1.PersistenceExpansionStage) error {
+	rmq.Status.PersistenceExpansionStage = stage
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update persistence expansion stage", "stage", stage)
 		return err
 	}
-
 	return nil
 }
 
-func (r *RabbitmqClusterReconciler) updatePVC(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, replicas int32, desiredCapacity k8sresource.Quantity) error {
+// fileSystemResizePending returns true if the volume of the given PVC has been resized but not its file system.
+// Volume plugins not supporting online expansion resize the file system when the volume is mounted again.
+func fileSystemResizePending(PVC *corev1.PersistentVolumeClaim) bool {
+	for _, c := range PVC.Status.Conditions {
+		if c.Type == corev1.PersistentVolumeClaimFileSystemResizePending && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// pvcResizePending returns why the resize of the given PVC has not completed yet, or an empty string if it has completed
+func pvcResizePending(PVC *corev1.PersistentVolumeClaim) string {
+	for _, c := range PVC.Status.Conditions {
+		if c.Type == corev1.PersistentVolumeClaimResizing && c.Status == corev1.ConditionTrue {
+			return "volume resize in progress"
+		}
+	}
+	requested := PVC.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := PVC.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(requested) == -1 {
+		return fmt.Sprintf("capacity %s is less than requested storage %s", capacity.String(), requested.String())
+	}
+	return ""
+}
+
+// updatePVCs updates the requested storage of all existing PVCs to the given capacities per volume claim template.
+// PVCs which already request the desired capacity are left untouched, so that this function can be run repeatedly.
+func (r *RabbitmqClusterReconciler) updatePVCs(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, desiredCapacities map[string]k8sresource.Quantity) error {
 	logger := ctrl.LoggerFrom(ctx)
 	logger.Info("expanding PersistentVolumeClaims")
 
-	for i := 0; i < int(replicas); i++ {
-		PVCName := rmq.PVCName(i)
-		PVC := corev1.PersistentVolumeClaim{}
+	for _, templateName := range sortedTemplateNames(desiredCapacities) {
+		desiredCapacity := desiredCapacities[templateName]
+		for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+			PVCName := pvcName(rmq, templateName, i)
+			PVC := corev1.PersistentVolumeClaim{}
 
-		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: PVCName}, &PVC); err != nil {
-			msg := "failed to get PersistentVolumeClaim"
-			logger.Error(err, msg, "PersistentVolumeClaim", PVCName)
-			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, PVCName))
-			return fmt.Errorf("%s %s: %v", msg, PVCName, err)
-		}
-		PVC.Spec.Resources.Requests[corev1.ResourceStorage] = desiredCapacity
-		if err := r.Client.Update(ctx, &PVC, &client.UpdateOptions{}); err != nil {
-			msg := "failed to update PersistentVolumeClaim"
-			logger.Error(err, msg, "PersistentVolumeClaim", PVCName)
-			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, PVCName))
-			return fmt.Errorf("%s %s: %v", msg, PVCName, err)
+			if err := r.Client.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: PVCName}, &PVC); err != nil {
+				if k8serrors.IsNotFound(err) {
+					// the PVC will be created by the StatefulSet with the desired capacity
+					continue
+				}
+				msg := "failed to get PersistentVolumeClaim"
+				logger.Error(err, msg, "PersistentVolumeClaim", PVCName)
+				r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, PVCName))
+				return fmt.Errorf("%s %s: %v", msg, PVCName, err)
+			}
+			currentCapacity := PVC.Spec.Resources.Requests[corev1.ResourceStorage]
+			if currentCapacity.Cmp(desiredCapacity) != -1 {
+				continue
+			}
+			PVC.Spec.Resources.Requests[corev1.ResourceStorage] = desiredCapacity
+			if err := r.Client.Update(ctx, &PVC, &client.UpdateOptions{}); err != nil {
+				msg := "failed to update PersistentVolumeClaim"
+				logger.Error(err, msg, "PersistentVolumeClaim", PVCName)
+				r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, PVCName))
+				return fmt.Errorf("%s %s: %v", msg, PVCName, err)
+			}
+			logger.Info("successfully expanded", "PVC", PVCName)
 		}
-		logger.Info("successfully expanded", "PVC", PVCName)
 	}
 	return nil
 }
 
-// returns true if desired storage capacity is larger than the current storage; returns false when current and desired capacity is the same
-// errors when desired capacity is less than current capacity because PVC shrink is not supported by k8s
-func (r *RabbitmqClusterReconciler) needsPVCExpand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) (bool, error) {
+// returns the desired storage capacities of all volume claim templates whose desired capacity is larger than their current capacity;
+// returns an empty map when current and desired capacities are the same
+// errors when a desired capacity is less than the current capacity because PVC shrink is not supported by k8s
+func (r *RabbitmqClusterReconciler) needsPVCExpand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) (map[string]k8sresource.Quantity, error) {
 	logger := ctrl.LoggerFrom(ctx)
 
-	currentCapacity, err := persistenceStorageCapacity(current.Spec.VolumeClaimTemplates)
-	if err != nil {
-		return false, err
+	currentCapacities := storageCapacities(current.Spec.VolumeClaimTemplates)
+	desiredCapacities := storageCapacities(desired.Spec.VolumeClaimTemplates)
+
+	resize := map[string]k8sresource.Quantity{}
+	for _, name := range sortedTemplateNames(desiredCapacities) {
+		currentCapacity, ok := currentCapacities[name]
+		if !ok {
+			// volume claim templates cannot be added to an existing StatefulSet
+			continue
+		}
+		desiredCapacity := desiredCapacities[name]
+		cmp := currentCapacity.Cmp(desiredCapacity)
+
+		// desired storage capacity is larger than the current capacity; PVC needs expansion
+		if cmp == -1 {
+			resize[name] = desiredCapacity
+		}
+
+		// desired storage capacity is less than the current capacity; logs and records a warning event
+		if cmp == 1 {
+			msg := "shrinking persistent volumes is not supported"
+			logger.Error(errors.New("unsupported operation"), msg, "template", name)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", msg)
+			return nil, errors.New(msg)
+		}
 	}
+	return resize, nil
+}
 
-	desiredCapacity, err := persistenceStorageCapacity(desired.Spec.VolumeClaimTemplates)
-	if err != nil {
-		return false, err
+// verifyVolumeClaimTemplatesUnchanged errors if volume claim templates were added or removed, e.g. through spec.persistence.volumes.
+// The Pod template of the StatefulSet would refer to volume claim templates which cannot be added to the existing StatefulSet,
+// and the data of a removed volume would be lost.
+func (r *RabbitmqClusterReconciler) verifyVolumeClaimTemplatesUnchanged(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) error {
+	currentNames := sortedTemplateNames(storageCapacities(current.Spec.VolumeClaimTemplates))
+	desiredNames := sortedTemplateNames(storageCapacities(desired.Spec.VolumeClaimTemplates))
+	if strings.Join(currentNames, ",") == strings.Join(desiredNames, ",") {
+		return nil
 	}
+	msg := "adding or removing persistent volumes is not supported"
+	ctrl.LoggerFrom(ctx).Error(errors.New("unsupported operation"), msg, "current", currentNames, "desired", desiredNames)
+	r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", msg)
+	return errors.New(msg)
+}
 
-	cmp := currentCapacity.Cmp(desiredCapacity)
+// verifyVolumeExpansionAllowed errors if the StorageClass of any PVC to expand does not allow volume expansion.
+// Expanding PVCs of such a StorageClass would be rejected by the API server after the StatefulSet has already been deleted.
+func (r *RabbitmqClusterReconciler) verifyVolumeExpansionAllowed(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current *appsv1.StatefulSet, resize map[string]k8sresource.Quantity) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	for _, t := range current.Spec.VolumeClaimTemplates {
+		if _, ok := resize[t.Name]; !ok {
+			continue
+		}
+		storageClassName, err := r.pvcStorageClassName(ctx, rmq, t)
+		if err != nil {
+			return err
+		}
 
-	// desired storage capacity is larger than the current capacity; PVC needs expansion
-	if cmp == -1 {
-		return true, nil
+		storageClass := &storagev1.StorageClass{}
+		if err := r.Get(ctx, types.NamespacedName{Name: storageClassName}, storageClass); err != nil {
+			msg := "failed to get StorageClass"
+			logger.Error(err, msg, "StorageClass", storageClassName)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, storageClassName))
+			return fmt.Errorf("%s %s: %v", msg, storageClassName, err)
+		}
+		if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+			msg := fmt.Sprintf("StorageClass %s does not allow volume expansion", storageClassName)
+			logger.Error(errors.New("unsupported operation"), msg, "template", t.Name)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", msg)
+			return errors.New(msg)
+		}
 	}
+	return nil
+}
 
-	// desired storage capacity is less than the current capacity; logs and records a warning event
-	if cmp == 1 {
-		msg := "shrinking persistent volumes is not supported"
-		logger.Error(errors.New("unsupported operation"), msg)
-		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", msg)
-		return false, errors.New(msg)
+// pvcStorageClassName returns the StorageClass of the first PVC created from the given template.
+// It falls back to the StorageClass of the template and the default StorageClass.
+func (r *RabbitmqClusterReconciler) pvcStorageClassName(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, template corev1.PersistentVolumeClaim) (string, error) {
+	PVC := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: pvcName(rmq, template.Name, 0)}, PVC)
+	if client.IgnoreNotFound(err) != nil {
+		return "", err
 	}
-	return false, nil
+	if err == nil && PVC.Spec.StorageClassName != nil && *PVC.Spec.StorageClassName != "" {
+		return *PVC.Spec.StorageClassName, nil
+	}
+	if template.Spec.StorageClassName != nil && *template.Spec.StorageClassName != "" {
+		return *template.Spec.StorageClassName, nil
+	}
+
+	defaultStorageClass, err := r.defaultStorageClassName(ctx)
+	if err != nil {
+		return "", err
+	}
+	if defaultStorageClass != "" {
+		return defaultStorageClass, nil
+	}
+	msg := "cannot determine StorageClass of PersistentVolumeClaim template"
+	r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, template.Name))
+	return "", fmt.Errorf("%s %s", msg, template.Name)
 }
 
-func persistenceStorageCapacity(templates []corev1.PersistentVolumeClaim) (k8sresource.Quantity, error) {
-	for _, t := range templates {
-		if t.Name == "persistence" {
-			return t.Spec.Resources.Requests[corev1.ResourceStorage], nil
+// defaultStorageClassName returns the name of the default StorageClass, or an empty string if there is no default StorageClass
+func (r *RabbitmqClusterReconciler) defaultStorageClassName(ctx context.Context) (string, error) {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := r.List(ctx, storageClasses); err != nil {
+		return "", err
+	}
+	for _, sc := range storageClasses.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+			return sc.Name, nil
 		}
 	}
-	return k8sresource.Quantity{}, errors.New("cannot find PersistentVolumeClaim 'persistence'")
+	return "", nil
+}
+
+func storageCapacities(templates []corev1.PersistentVolumeClaim) map[string]k8sresource.Quantity {
+	capacities := make(map[string]k8sresource.Quantity, len(templates))
+	for _, t := range templates {
+		capacities[t.Name] = t.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+	return capacities
+}
+
+func sortedTemplateNames(capacities map[string]k8sresource.Quantity) []string {
+	names := make([]string, 0, len(capacities))
+	for name := range capacities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pvcName returns the name of the PVC created by the StatefulSet for the given volume claim template and Pod index
+func pvcName(rmq *rabbitmqv1beta1.RabbitmqCluster, templateName string, i int) string {
+	return strings.Join([]string{templateName, rmq.ChildResourceName("server"), strconv.Itoa(i)}, "-")
 }
 
 // deleteSts deletes a sts without deleting pods and PVCs
 // using DeletePropagationPolicy set to 'Orphan'
-func (r *RabbitmqClusterReconciler) deleteSts(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+// It does not wait for the deletion to complete.
+func (r *RabbitmqClusterReconciler) deleteSts(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, sts *appsv1.StatefulSet) error {
 	logger := ctrl.LoggerFrom(ctx)
-	logger.Info("deleting statefulSet (pods won't be deleted)", "statefulSet", rmq.ChildResourceName("server"))
+	logger.Info("deleting statefulSet (pods won't be deleted)", "statefulSet", sts.Name)
 	deletePropagationPolicy := metav1.DeletePropagationOrphan
 	deleteOptions := &client.DeleteOptions{PropagationPolicy: &deletePropagationPolicy}
-	currentSts, err := r.statefulSet(ctx, rmq)
-	if err != nil {
-		return err
-	}
-	if err := r.Delete(ctx, currentSts, deleteOptions); err != nil {
+	if err := r.Delete(ctx, sts, deleteOptions); client.IgnoreNotFound(err) != nil {
 		msg := "failed to delete statefulSet"
-		logger.Error(err, msg, "statefulSet", currentSts.Name)
-		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, currentSts.Name))
-		return fmt.Errorf("%s %s: %v", msg, currentSts.Name, err)
+		logger.Error(err, msg, "statefulSet", sts.Name)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, sts.Name))
+		return fmt.Errorf("%s %s: %v", msg, sts.Name, err)
 	}
-
-	if err := retryWithInterval(logger, "delete statefulSet", 10, 3*time.Second, func() bool {
-		_, getErr := r.statefulSet(ctx, rmq)
-		return k8serrors.IsNotFound(getErr)
-	}); err != nil {
-		msg := "statefulSet not deleting after 30 seconds"
-		logger.Error(err, msg, "statefulSet", currentSts.Name)
-		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, currentSts.Name))
-		return fmt.Errorf("%s %s: %v", msg, currentSts.Name, err)
-	}
-	logger.Info("statefulSet deleted", "statefulSet", currentSts.Name)
 	return nil
 }
-
-func retryWithInterval(logger logr.Logger, msg string, retry int, interval time.Duration, f func() bool) (err error) {
-	for i := 0; i < retry; i++ {
-		if ok := f(); ok {
-			return
-		}
-		time.Sleep(interval)
-		logger.Info("retrying again", "action", msg, "interval", interval, "attempt", i+1)
-	}
-	return fmt.Errorf("failed to %s after %d retries", msg, retry)
-}
@@ -7,7 +7,9 @@ import (
 	. "github.com/onsi/gomega"
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -89,3 +91,249 @@ var _ = Describe("Persistence", func() {
 		})
 	})
 })
+
+var _ = Describe("Persistence volumes", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+	)
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-add-volume",
+				Namespace: defaultNamespace,
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
+
+	It("does not allow adding volumes to an existing RabbitmqCluster", func() {
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+				{Role: rabbitmqv1beta1.PersistenceVolumeQuorumWAL},
+			}
+		})).To(Succeed())
+
+		Eventually(func() string {
+			rabbit := &rabbitmqv1beta1.RabbitmqCluster{}
+			Expect(client.Get(ctx, runtimeClient.ObjectKey{Name: cluster.Name, Namespace: defaultNamespace}, rabbit)).To(Succeed())
+			for _, condition := range rabbit.Status.Conditions {
+				if condition.Type == status.ReconcileSuccess {
+					return condition.Reason + ": " + condition.Message
+				}
+			}
+			return ""
+		}, 5).Should(Equal("FailedReconcilePVC: adding or removing persistent volumes is not supported"))
+		Expect(aggregateEventMsgs(ctx, cluster, "FailedReconcilePersistence")).To(
+			ContainSubstring("adding or removing persistent volumes is not supported"))
+
+		sts := statefulSet(ctx, cluster)
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		Expect(extractContainer(sts.Spec.Template.Spec.Containers, "rabbitmq").VolumeMounts).NotTo(
+			ContainElement(corev1.VolumeMount{Name: "quorum-wal", MountPath: "/var/lib/rabbitmq/quorum-wal/"}))
+	})
+})
+
+var _ = Describe("Persistence expansion", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		storageClass     *storagev1.StorageClass
+		defaultNamespace = "default"
+		ctx              = context.Background()
+	)
+
+	createCluster := func(name string, allowVolumeExpansion bool) {
+		storageClass = &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: name},
+			Provisioner:          "kubernetes.io/no-provisioner",
+			AllowVolumeExpansion: pointer.BoolPtr(allowVolumeExpansion),
+		}
+		Expect(client.Create(ctx, storageClass)).To(Succeed())
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(1),
+				Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+					StorageClassName: pointer.StringPtr(name),
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	}
+
+	expansionStage := func() rabbitmqv1beta1.PersistenceExpansionStage {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq.Status.PersistenceExpansionStage
+	}
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		Expect(client.Delete(ctx, storageClass)).To(Succeed())
+		Eventually(func() bool {
+			rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+			err := client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)
+			return apierrors.IsNotFound(err)
+		}, 5).Should(BeTrue())
+	})
+
+	When("the StorageClass does not allow volume expansion", func() {
+		BeforeEach(func() {
+			createCluster("rabbitmq-no-expansion", false)
+		})
+
+		It("does not delete the StatefulSet and sets a warning", func() {
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				storage := k8sresource.MustParse("20Gi")
+				r.Spec.Persistence.Storage = &storage
+			})).To(Succeed())
+
+			Eventually(func() string {
+				return aggregateEventMsgs(ctx, cluster, "FailedReconcilePersistence")
+			}, 5).Should(ContainSubstring("StorageClass rabbitmq-no-expansion does not allow volume expansion"))
+
+			Consistently(func() bool {
+				return statefulSet(ctx, cluster).DeletionTimestamp.IsZero()
+			}, 3, 1).Should(BeTrue())
+			Expect(expansionStage()).To(BeEmpty())
+		})
+	})
+
+	When("the StorageClass allows volume expansion", func() {
+		var pvc *corev1.PersistentVolumeClaim
+
+		BeforeEach(func() {
+			createCluster("rabbitmq-expansion", true)
+			// envtest does not run the StatefulSet controller
+			pvc = &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "persistence-" + cluster.ChildResourceName("server") + "-0",
+					Namespace: defaultNamespace,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: pointer.StringPtr("rabbitmq-expansion"),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse("10Gi")},
+					},
+				},
+			}
+			Expect(client.Create(ctx, pvc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(client.Delete(ctx, pvc)).To(Succeed())
+		})
+
+		It("expands the PVCs in stages recorded in status", func() {
+			twentyG := k8sresource.MustParse("20Gi")
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Spec.Persistence.Storage = &twentyG
+			})).To(Succeed())
+
+			By("deleting the StatefulSet", func() {
+				Eventually(expansionStage, 5).Should(Equal(rabbitmqv1beta1.PersistenceExpansionDeletingStatefulSet))
+				Eventually(func() bool {
+					return statefulSet(ctx, cluster).DeletionTimestamp.IsZero()
+				}, 5).Should(BeFalse())
+			})
+
+			By("expanding the PVCs once the StatefulSet is deleted", func() {
+				// envtest does not run the garbage collector which removes the orphan finalizer
+				sts := statefulSet(ctx, cluster)
+				sts.Finalizers = nil
+				Expect(client.Update(ctx, sts)).To(Succeed())
+
+				Eventually(expansionStage, 5).Should(Equal(rabbitmqv1beta1.PersistenceExpansionResizing))
+				Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(twentyG))
+				Eventually(func() k8sresource.Quantity {
+					sts := &appsv1.StatefulSet{}
+					if err := client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server"), Namespace: defaultNamespace}, sts); err != nil {
+						return k8sresource.Quantity{}
+					}
+					return sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+				}, 5).Should(Equal(twentyG))
+			})
+
+			By("reporting Pods which need a restart to resize their file systems", func() {
+				Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+					Type:   corev1.PersistentVolumeClaimFileSystemResizePending,
+					Status: corev1.ConditionTrue,
+				}}
+				Expect(client.Status().Update(ctx, pvc)).To(Succeed())
+				Eventually(func() []string {
+					rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+					Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(cluster), rmq)).To(Succeed())
+					return rmq.Status.PersistenceExpansionPendingRestart
+				}, 15).Should(ConsistOf(cluster.ChildResourceName("server") + "-0"))
+				Expect(expansionStage()).To(Equal(rabbitmqv1beta1.PersistenceExpansionResizing))
+				Eventually(func() string {
+					return aggregateEventMsgs(ctx, cluster, "PersistenceExpansionPendingRestart")
+				}, 5).Should(ContainSubstring("delete the pod to complete the expansion"))
+			})
+
+			By("clearing the stage once the PVCs are resized", func() {
+				Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				pvc.Status.Conditions = nil
+				pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: twentyG}
+				Expect(client.Status().Update(ctx, pvc)).To(Succeed())
+				Eventually(expansionStage, 15).Should(BeEmpty())
+				rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+				Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(cluster), rmq)).To(Succeed())
+				Expect(rmq.Status.PersistenceExpansionPendingRestart).To(BeEmpty())
+				Eventually(func() string {
+					return aggregateEventMsgs(ctx, cluster, "SuccessfulExpansion")
+				}, 5).Should(ContainSubstring("expanded PersistentVolumeClaims"))
+			})
+		})
+
+		It("starts over when the capacity is increased while the PVCs are resized", func() {
+			volumeClaimTemplateCapacity := func() k8sresource.Quantity {
+				sts := &appsv1.StatefulSet{}
+				if err := client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server"), Namespace: defaultNamespace}, sts); err != nil {
+					return k8sresource.Quantity{}
+				}
+				return sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+			}
+			expandTo := func(capacity k8sresource.Quantity) {
+				Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+					r.Spec.Persistence.Storage = &capacity
+				})).To(Succeed())
+				Eventually(expansionStage, 5).Should(Equal(rabbitmqv1beta1.PersistenceExpansionDeletingStatefulSet))
+				Eventually(func() bool {
+					return statefulSet(ctx, cluster).DeletionTimestamp.IsZero()
+				}, 5).Should(BeFalse())
+				// envtest does not run the garbage collector which removes the orphan finalizer
+				sts := statefulSet(ctx, cluster)
+				sts.Finalizers = nil
+				Expect(client.Update(ctx, sts)).To(Succeed())
+				Eventually(expansionStage, 5).Should(Equal(rabbitmqv1beta1.PersistenceExpansionResizing))
+				Eventually(volumeClaimTemplateCapacity, 5).Should(Equal(capacity))
+			}
+
+			By("expanding the PVCs to 20Gi", func() {
+				expandTo(k8sresource.MustParse("20Gi"))
+			})
+
+			By("expanding the PVCs to 30Gi before the first resize completed", func() {
+				thirtyG := k8sresource.MustParse("30Gi")
+				expandTo(thirtyG)
+				Expect(client.Get(ctx, runtimeClient.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(thirtyG))
+			})
+		})
+	})
+})
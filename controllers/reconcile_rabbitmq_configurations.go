@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -14,6 +15,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clientretry "k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -23,11 +26,14 @@ const (
 	serverConfAnnotation    = "rabbitmq.com/serverConfUpdatedAt"
 	stsRestartAnnotation    = "rabbitmq.com/lastRestartAt"
 	stsCreateAnnotation     = "rabbitmq.com/createdAt"
+	// runtimeConfAnnotation marks server-conf ConfigMap changes which are applied to the running RabbitMQ nodes instead of restarting them
+	runtimeConfAnnotation = "rabbitmq.com/runtimeConfUpdatedAt"
 )
 
 // Annotates an object depending on object type and operationResult.
 // These annotations are temporary markers used in later reconcile loops to perform some action (such as restarting the StatefulSet or executing RabbitMQ CLI commands)
-func (r *RabbitmqClusterReconciler) annotateIfNeeded(ctx context.Context, logger logr.Logger, builder resource.ResourceBuilder, operationResult controllerutil.OperationResult, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+// previous and current are the object before and after the update.
+func (r *RabbitmqClusterReconciler) annotateIfNeeded(ctx context.Context, logger logr.Logger, builder resource.ResourceBuilder, operationResult controllerutil.OperationResult, rmq *rabbitmqv1beta1.RabbitmqCluster, previous, current client.Object) error {
 	var (
 		obj           client.Object
 		objName       string
@@ -51,6 +57,9 @@ func (r *RabbitmqClusterReconciler) annotateIfNeeded(ctx context.Context, logger
 		obj = &corev1.ConfigMap{}
 		objName = rmq.ChildResourceName(resource.ServerConfigMapName)
 		annotationKey = serverConfAnnotation
+		if !resource.ConfigRequiresRestart(previous.(*corev1.ConfigMap).Data, current.(*corev1.ConfigMap).Data) {
+			annotationKey = runtimeConfAnnotation
+		}
 
 	case *resource.StatefulSetBuilder:
 		if operationResult != controllerutil.OperationResultCreated {
@@ -88,7 +97,7 @@ func (r *RabbitmqClusterReconciler) restartStatefulSetIfNeeded(ctx context.Conte
 	serverConfigUpdatedAt, ok := serverConf.Annotations[serverConfAnnotation]
 	if !ok {
 		// server-conf configmap hasn't been updated; no need to restart sts
-		return 0, nil
+		return 0, r.setDeferredAction(ctx, rmq, rabbitmqv1beta1.DeferredRestart, false)
 	}
 
 	sts, err := r.statefulSet(ctx, rmq)
@@ -100,7 +109,11 @@ func (r *RabbitmqClusterReconciler) restartStatefulSetIfNeeded(ctx context.Conte
 	stsRestartedAt, ok := sts.Spec.Template.ObjectMeta.Annotations[stsRestartAnnotation]
 	if ok && stsRestartedAt > serverConfigUpdatedAt {
 		// sts was updated after the last server-conf configmap update; no need to restart sts
-		return 0, nil
+		return 0, r.setDeferredAction(ctx, rmq, rabbitmqv1beta1.DeferredRestart, false)
+	}
+
+	if deferred, err := r.deferDisruptiveAction(ctx, rmq, rabbitmqv1beta1.DeferredRestart); err != nil || deferred {
+		return 0, err
 	}
 
 	if err := clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
@@ -112,6 +125,10 @@ func (r *RabbitmqClusterReconciler) restartStatefulSetIfNeeded(ctx context.Conte
 			sts.Spec.Template.ObjectMeta.Annotations = make(map[string]string)
 		}
 		sts.Spec.Template.ObjectMeta.Annotations[stsRestartAnnotation] = time.Now().Format(time.RFC3339)
+		if rmq.CanaryRollout() && *sts.Spec.Replicas > 1 {
+			// restart the canary Pod first
+			sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(*sts.Spec.Replicas - 1)}
+		}
 		return r.Update(ctx, sts)
 	}); err != nil {
 		msg := fmt.Sprintf("failed to restart StatefulSet %s; rabbitmq.conf configuration may be outdated", rmq.ChildResourceName("server"))
@@ -139,3 +156,30 @@ func pluginsConfigUpdatedRecently(cfg *corev1.ConfigMap) (bool, error) {
 	}
 	return time.Since(annotationTime).Seconds() < 2, nil
 }
+
+// There are 2 paths how settings of rabbitmq.conf which RabbitMQ can change at runtime are applied:
+// 1. When the StatefulSet is (re)started, the RabbitMQ nodes read them from the configuration file.
+// 2. When only such settings changed in the server-conf ConfigMap, they are set on every node with rabbitmqctl (without the need to re-start the nodes).
+// This method implements the 2nd path.
+func (r *RabbitmqClusterReconciler) runSetRuntimeConfigCommands(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, configMap *corev1.ConfigMap) error {
+	logger := ctrl.LoggerFrom(ctx)
+	commands, err := resource.RuntimeConfigCommands(configMap.Data)
+	if err != nil {
+		return fmt.Errorf("failed to parse rabbitmq.conf of ConfigMap %s: %w", configMap.Name, err)
+	}
+	if len(commands) > 0 {
+		cmd := strings.Join(commands, " && ")
+		for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+			podName := serverPodName(rmq, i)
+			stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd)
+			if err != nil {
+				msg := "failed to apply runtime configuration on pod"
+				logger.Error(err, msg, "pod", podName, "command", cmd, "stdout", stdout, "stderr", stderr)
+				r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcile", fmt.Sprintf("%s %s", msg, podName))
+				return fmt.Errorf("%s %s: %v", msg, podName, err)
+			}
+		}
+		logger.Info("successfully applied runtime configuration")
+	}
+	return r.deleteAnnotation(ctx, configMap, runtimeConfAnnotation)
+}
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var _ = Describe("Reconcile rabbitmq Configurations", func() {
@@ -40,6 +41,9 @@ var _ = Describe("Reconcile rabbitmq Configurations", func() {
 				if testCase == "additional-config" {
 					r.Spec.Rabbitmq.AdditionalConfig = "test_config=0"
 				}
+				if testCase == "config" {
+					r.Spec.Rabbitmq.Config = map[string]string{"heartbeat": "30"}
+				}
 				if testCase == "advanced-config" {
 					r.Spec.Rabbitmq.AdvancedConfig = "sample-advanced-config."
 				}
@@ -75,7 +79,56 @@ var _ = Describe("Reconcile rabbitmq Configurations", func() {
 		},
 
 		Entry("spec.rabbitmq.additionalConfig is updated", "additional-config"),
+		Entry("spec.rabbitmq.config is updated", "config"),
 		Entry("spec.rabbitmq.advancedConfig is updated", "advanced-config"),
 		Entry("spec.rabbitmq.envConfig is updated", "env-config"),
 	)
+
+	It("applies settings which can be changed at runtime without restarting the StatefulSet", func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-runtime-config",
+				Namespace: defaultNamespace,
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+
+		sts := statefulSet(ctx, cluster)
+		sts.Status.Replicas = 1
+		sts.Status.ReadyReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Rabbitmq.Config = map[string]string{
+				"vm_memory_high_watermark.relative": "0.6",
+				"log.console.level":                 "debug",
+			}
+		})).To(Succeed())
+
+		Eventually(fakeExecutor.ExecutedCommands, 5).Should(ContainElement(command{"sh", "-c",
+			"rabbitmqctl set_disk_free_limit 2GB && rabbitmqctl set_vm_memory_high_watermark 0.6 && rabbitmqctl set_log_level debug"}))
+		Eventually(func() map[string]string {
+			return configMap(ctx, cluster, "server-conf").Annotations
+		}, 5).ShouldNot(HaveKey("rabbitmq.com/runtimeConfUpdatedAt"))
+
+		Expect(configMap(ctx, cluster, "server-conf").Annotations).NotTo(HaveKey("rabbitmq.com/serverConfUpdatedAt"))
+		Expect(statefulSet(ctx, cluster).Spec.Template.Annotations).NotTo(HaveKey("rabbitmq.com/lastRestartAt"))
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		Expect(rmq.Status.PendingRestart).To(BeFalse())
+
+		By("restarting the StatefulSet if a setting is removed")
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Rabbitmq.Config = map[string]string{
+				"vm_memory_high_watermark.relative": "0.6",
+			}
+		})).To(Succeed())
+		Eventually(func() map[string]string {
+			return statefulSet(ctx, cluster).Spec.Template.Annotations
+		}, 5).Should(HaveKey("rabbitmq.com/lastRestartAt"))
+
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
 })
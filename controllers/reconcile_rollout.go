@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientretry "k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A paused canary rollout is resumed when this annotation is set to "true" on the RabbitmqCluster
+const resumeRolloutAnnotation = "rabbitmq.com/resumeRollout"
+
+// A canary rollout is driven by the partition of the StatefulSet:
+//  1. when the Pod template of an existing StatefulSet changes, the partition is set to the highest ordinal,
+//     so that the StatefulSet controller only updates the canary Pod
+//  2. once all Pods with an ordinal at or above the partition are updated and all Pods are ready,
+//     the health checks run on the updated Pods and the partition is moved down by spec.rollout.stepSize;
+//     outside of maintenance windows, the partition is kept until the next window opens
+//  3. if a health check fails, the RolloutPaused condition is set to True and the partition is kept
+//     until the rollout is resumed with the resume annotation or a new change of the Pod template starts a new rollout
+//
+// startCanaryRollout implements the first step. It is called after the StatefulSet builder updated the StatefulSet.
+// It returns true if a rollout starts.
+func startCanaryRollout(rmq *rabbitmqv1beta1.RabbitmqCluster, previous, desired client.Object) bool {
+	sts, ok := desired.(*appsv1.StatefulSet)
+	if !ok || !rmq.CanaryRollout() || previous.GetResourceVersion() == "" || *sts.Spec.Replicas < 2 {
+		return false
+	}
+	if previous.GetAnnotations()[podTemplateHashAnnotation] == sts.Annotations[podTemplateHashAnnotation] {
+		return false
+	}
+	sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{
+		Partition: pointer.Int32Ptr(*sts.Spec.Replicas - 1),
+	}
+	return true
+}
+
+// reconcileRollout moves the partition of a canary rollout down once the updated Pods pass the health checks.
+// A non-zero duration is returned while the rollout is in progress.
+func (r *RabbitmqClusterReconciler) reconcileRollout(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	if !rmq.CanaryRollout() {
+		return 0, r.removeRolloutCondition(ctx, rmq)
+	}
+
+	sts, err := r.statefulSet(ctx, rmq)
+	if err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	partition := stsPartition(sts)
+	if partition == 0 {
+		if err := r.setDeferredAction(ctx, rmq, rabbitmqv1beta1.DeferredRollout, false); err != nil {
+			return 0, err
+		}
+		return 0, r.setRolloutCondition(ctx, rmq, corev1.ConditionFalse, "RolloutComplete", "all Pods are updated")
+	}
+
+	if paused := rolloutCondition(rmq); paused != nil && paused.Status == corev1.ConditionTrue {
+		if rmq.Annotations[resumeRolloutAnnotation] != "true" {
+			return 0, nil
+		}
+		logger.Info("resuming canary rollout")
+		if err := r.deleteAnnotation(ctx, rmq, resumeRolloutAnnotation); err != nil {
+			return 0, err
+		}
+		if err := r.setRolloutCondition(ctx, rmq, corev1.ConditionFalse, "RolloutResumed", "rollout was resumed"); err != nil {
+			return 0, err
+		}
+	}
+
+	// drained nodes are not ready
+	replicas := *sts.Spec.Replicas
+	if sts.Status.UpdatedReplicas < replicas-partition || sts.Status.ReadyReplicas+int32(len(rmq.Status.DrainedNodes)) < replicas {
+		logger.Info("waiting for updated Pods to be ready", "partition", partition)
+		return 10 * time.Second, nil
+	}
+
+	for i := partition; i < replicas; i++ {
+		podName := serverPodName(rmq, int(i))
+		if err := r.rolloutHealthCheck(ctx, rmq, podName); err != nil {
+			msg := fmt.Sprintf("paused rollout: health check failed on pod %s", podName)
+			logger.Error(err, msg)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "RolloutPaused", msg)
+			return 0, r.setRolloutCondition(ctx, rmq, corev1.ConditionTrue, "HealthCheckFailed", fmt.Sprintf("%s: %v", msg, err))
+		}
+	}
+
+	// moving the partition restarts the next Pods
+	if deferred, err := r.deferDisruptiveAction(ctx, rmq, rabbitmqv1beta1.DeferredRollout); err != nil || deferred {
+		return 0, err
+	}
+
+	stepSize := int32(1)
+	if rmq.Spec.Rollout.StepSize != nil {
+		stepSize = *rmq.Spec.Rollout.StepSize
+	}
+	next := partition - stepSize
+	if next < 0 {
+		next = 0
+	}
+	if err := clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		sts := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, types.NamespacedName{Name: rmq.ChildResourceName("server"), Namespace: rmq.Namespace}, sts); err != nil {
+			return err
+		}
+		sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(next)}
+		return r.Update(ctx, sts)
+	}); err != nil {
+		return 0, err
+	}
+
+	msg := fmt.Sprintf("updating Pods with ordinal %d and above", next)
+	logger.Info(msg)
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "RolloutProgressing", msg)
+	return 10 * time.Second, r.setRolloutCondition(ctx, rmq, corev1.ConditionFalse, "RolloutProgressing", msg)
+}
+
+// rolloutHealthCheck checks that the node runs without local alarms and that all nodes of the cluster are running,
+// and optionally publishes and gets a message through a temporary queue on the node.
+// Drained nodes are under maintenance and not required to be running.
+func (r *RabbitmqClusterReconciler) rolloutHealthCheck(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) error {
+	cmd := "rabbitmq-diagnostics -q check_running && rabbitmq-diagnostics -q check_local_alarms"
+	if stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd); err != nil {
+		return fmt.Errorf("%s: %v: %s", cmd, err, strings.TrimSpace(stdout+stderr))
+	}
+
+	clusterStatus := r.clusterStatus(ctx, rmq, podName)
+	if clusterStatus == nil {
+		return fmt.Errorf("failed to get cluster status")
+	}
+	for i := 0; i < int(*rmq.Spec.Replicas); i++ {
+		if containsString(rmq.Status.DrainedNodes, serverPodName(rmq, i)) {
+			continue
+		}
+		node := rabbitmqNodeName(rmq, serverPodName(rmq, i))
+		if state := clusterStatus.nodeState(node); state != rabbitmqv1beta1.NodeStateRunning {
+			return fmt.Errorf("node %s is %s", node, state)
+		}
+	}
+
+	if rmq.Spec.Rollout.SmokeTest {
+		cmd := smokeTestCommand(rmq, podName)
+		if stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "sh", "-c", cmd); err != nil {
+			return fmt.Errorf("smoke test: %v: %s", err, strings.TrimSpace(stdout+stderr))
+		}
+	}
+	return nil
+}
+
+// smokeTestCommand declares a queue on the node through the management API, publishes a message to it, gets it back, and deletes the queue.
+// rabbitmqadmin reads the default user credentials from the configuration file written by the setup container.
+func smokeTestCommand(rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) string {
+	admin := "rabbitmqadmin"
+	if rmq.DisableNonTLSListeners() {
+		admin += " --ssl --port=15671 --ssl-disable-hostname-verification"
+	}
+	if prefix := rmq.ManagementPathPrefix(); prefix != "" {
+		admin += " --path-prefix=" + prefix
+	}
+	queue := "rabbitmq-cluster-operator-smoke-test-" + podName
+	return fmt.Sprintf("%[1]s declare queue name=%[2]s durable=false && "+
+		"%[1]s publish routing_key=%[2]s payload=smoke-test && "+
+		"%[1]s get queue=%[2]s ackmode=ack_requeue_false | grep -q smoke-test; "+
+		"rc=$?; %[1]s delete queue name=%[2]s; exit $rc", admin, queue)
+}
+
+func stsPartition(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.UpdateStrategy.RollingUpdate == nil || sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+		return 0
+	}
+	return *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+func rolloutCondition(rmq *rabbitmqv1beta1.RabbitmqCluster) *status.RabbitmqClusterCondition {
+	for i := range rmq.Status.Conditions {
+		if rmq.Status.Conditions[i].Type == status.RolloutPaused {
+			return &rmq.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func (r *RabbitmqClusterReconciler) setRolloutCondition(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, conditionStatus corev1.ConditionStatus, reason, message string) error {
+	condition := rolloutCondition(rmq)
+	if condition != nil && condition.Status == conditionStatus && condition.Reason == reason && condition.Message == message {
+		return nil
+	}
+	if condition == nil {
+		rmq.Status.Conditions = append(rmq.Status.Conditions, status.RolloutPausedCondition(conditionStatus, reason, message))
+	} else {
+		rmq.Status.SetCondition(status.RolloutPaused, conditionStatus, reason, message)
+	}
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update RolloutPaused condition", "reason", reason)
+		return err
+	}
+	return nil
+}
+
+func (r *RabbitmqClusterReconciler) removeRolloutCondition(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	if rolloutCondition(rmq) == nil {
+		return nil
+	}
+	var conditions []status.RabbitmqClusterCondition
+	for _, condition := range rmq.Status.Conditions {
+		if condition.Type != status.RolloutPaused {
+			conditions = append(conditions, condition)
+		}
+	}
+	rmq.Status.Conditions = conditions
+	return r.Status().Update(ctx, rmq)
+}
@@ -0,0 +1,145 @@
+package controllers_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Canary rollout", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+		statusCommand    = command{"sh", "-c", "rabbitmq-diagnostics -q cluster_status --formatter json"}
+		healthCommand    = command{"sh", "-c", "rabbitmq-diagnostics -q check_running && rabbitmq-diagnostics -q check_local_alarms"}
+	)
+
+	partition := func() int32 {
+		return *statefulSet(ctx, cluster).Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+
+	rolloutCondition := func() *status.RabbitmqClusterCondition {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		for _, condition := range rmq.Status.Conditions {
+			if condition.Type == status.RolloutPaused {
+				return &condition
+			}
+		}
+		return nil
+	}
+
+	nodeName := func(i int) string {
+		return "rabbit@rabbitmq-canary-server-" + []string{"0", "1", "2"}[i] + ".rabbitmq-canary-nodes.default"
+	}
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-canary",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(3),
+				Rollout: &rabbitmqv1beta1.RabbitmqClusterRolloutSpec{
+					Mode: rabbitmqv1beta1.CanaryRolloutMode,
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+		Expect(partition()).To(Equal(int32(0)))
+
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Image = "rabbitmq:canary"
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
+
+	It("updates the canary Pod first and moves the partition down once it is healthy", func() {
+		By("setting the partition to the highest ordinal", func() {
+			Eventually(partition, 5).Should(Equal(int32(2)))
+			Expect(statefulSet(ctx, cluster).Spec.Template.Spec.Containers[0].Image).To(Equal("rabbitmq:canary"))
+			Expect(rolloutCondition()).NotTo(BeNil())
+			Expect(rolloutCondition().Status).To(Equal(corev1.ConditionFalse))
+		})
+
+		By("moving the partition down once the canary passes the health checks", func() {
+			fakeExecutor.SetStdout(statusCommand, `{"running_nodes": ["`+nodeName(0)+`", "`+nodeName(1)+`", "`+nodeName(2)+`"]}`)
+			sts := statefulSet(ctx, cluster)
+			sts.Status.Replicas = 3
+			sts.Status.ReadyReplicas = 3
+			sts.Status.UpdatedReplicas = 1
+			Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+			Eventually(partition, 5).Should(Equal(int32(1)))
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(healthCommand))
+			Expect(rolloutCondition().Reason).To(Equal("RolloutProgressing"))
+		})
+	})
+
+	It("keeps the partition outside of maintenance windows", func() {
+		Eventually(partition, 5).Should(Equal(int32(2)))
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.MaintenanceWindows = []rabbitmqv1beta1.MaintenanceWindow{{
+				Schedule: "0 0 1 1 *",
+				Duration: metav1.Duration{Duration: time.Minute},
+			}}
+		})).To(Succeed())
+
+		fakeExecutor.SetStdout(statusCommand, `{"running_nodes": ["`+nodeName(0)+`", "`+nodeName(1)+`", "`+nodeName(2)+`"]}`)
+		sts := statefulSet(ctx, cluster)
+		sts.Status.Replicas = 3
+		sts.Status.ReadyReplicas = 3
+		sts.Status.UpdatedReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+		Eventually(func() []rabbitmqv1beta1.DeferredAction {
+			rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+			return rmq.Status.DeferredActions
+		}, 5).Should(ContainElement(rabbitmqv1beta1.DeferredRollout))
+		Consistently(partition, 2).Should(Equal(int32(2)))
+	})
+
+	It("pauses the rollout if a health check fails", func() {
+		Eventually(partition, 5).Should(Equal(int32(2)))
+
+		fakeExecutor.SetStdout(statusCommand, `{"running_nodes": ["`+nodeName(0)+`", "`+nodeName(1)+`"]}`)
+		sts := statefulSet(ctx, cluster)
+		sts.Status.Replicas = 3
+		sts.Status.ReadyReplicas = 3
+		sts.Status.UpdatedReplicas = 1
+		Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+		Eventually(func() string {
+			if condition := rolloutCondition(); condition != nil && condition.Status == corev1.ConditionTrue {
+				return condition.Reason
+			}
+			return ""
+		}, 5).Should(Equal("HealthCheckFailed"))
+		Expect(rolloutCondition().Message).To(ContainSubstring(nodeName(2) + " is NotRunning"))
+		Consistently(partition, 2).Should(Equal(int32(2)))
+
+		By("resuming the rollout once annotated", func() {
+			fakeExecutor.SetStdout(statusCommand, `{"running_nodes": ["`+nodeName(0)+`", "`+nodeName(1)+`", "`+nodeName(2)+`"]}`)
+			Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+				r.Annotations = map[string]string{"rabbitmq.com/resumeRollout": "true"}
+			})).To(Succeed())
+
+			Eventually(partition, 5).Should(Equal(int32(1)))
+			Expect(rolloutCondition().Status).To(Equal(corev1.ConditionFalse))
+		})
+	})
+})
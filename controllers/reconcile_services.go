@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileNamedServices deletes the Services of entries removed from spec.services, and lists the Services of spec.services in status.
+func (r *RabbitmqClusterReconciler) reconcileNamedServices(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(rmq.Namespace), client.MatchingLabels(metadata.LabelSelector(rmq.Name)), client.HasLabels{resource.NamedServiceLabel}); err != nil {
+		return fmt.Errorf("failed to list Services: %w", err)
+	}
+
+	desired := map[string]bool{}
+	for _, s := range rmq.Spec.Services {
+		desired[s.Name] = true
+	}
+
+	var servicesStatus []rabbitmqv1beta1.RabbitmqClusterNamedServiceStatus
+	for i := range services.Items {
+		service := &services.Items[i]
+		if !metav1.IsControlledBy(service, rmq) {
+			continue
+		}
+		if !desired[service.Labels[resource.NamedServiceLabel]] {
+			if err := r.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
+				msg := fmt.Sprintf("failed to delete Service %s", service.Name)
+				logger.Error(err, msg)
+				r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedDelete", msg)
+				return err
+			}
+			msg := fmt.Sprintf("deleted Service %s removed from spec.services", service.Name)
+			logger.Info(msg)
+			r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulDelete", msg)
+			continue
+		}
+		serviceStatus := rabbitmqv1beta1.RabbitmqClusterNamedServiceStatus{
+			Name: service.Name,
+			Type: service.Spec.Type,
+		}
+		for _, p := range service.Spec.Ports {
+			serviceStatus.Listeners = append(serviceStatus.Listeners, p.Name)
+		}
+		servicesStatus = append(servicesStatus, serviceStatus)
+	}
+
+	sort.Slice(servicesStatus, func(i, j int) bool {
+		return servicesStatus[i].Name < servicesStatus[j].Name
+	})
+
+	if !reflect.DeepEqual(rmq.Status.Services, servicesStatus) {
+		rmq.Status.Services = servicesStatus
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Named Services", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+	)
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-named-services",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Services: []rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{
+					{Name: "amqp-internal", Type: corev1.ServiceTypeLoadBalancer, Listeners: []rabbitmqv1beta1.Listener{"amqp"}},
+					{Name: "management", Listeners: []rabbitmqv1beta1.Listener{"management"}},
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+	})
+
+	servicesStatus := func() []rabbitmqv1beta1.RabbitmqClusterNamedServiceStatus {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq.Status.Services
+	}
+
+	It("creates the Services and lists them in status", func() {
+		Eventually(servicesStatus, 5).Should(Equal([]rabbitmqv1beta1.RabbitmqClusterNamedServiceStatus{
+			{Name: "rabbitmq-named-services-amqp-internal", Type: corev1.ServiceTypeLoadBalancer, Listeners: []string{"amqp"}},
+			{Name: "rabbitmq-named-services-management", Type: corev1.ServiceTypeClusterIP, Listeners: []string{"management"}},
+		}))
+	})
+
+	It("deletes Services removed from spec.services", func() {
+		Eventually(servicesStatus, 5).Should(HaveLen(2))
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Services = r.Spec.Services[:1]
+		})).To(Succeed())
+
+		Eventually(func() bool {
+			err := client.Get(ctx, types.NamespacedName{Name: "rabbitmq-named-services-management", Namespace: defaultNamespace}, &corev1.Service{})
+			return k8serrors.IsNotFound(err)
+		}, 5).Should(BeTrue())
+		Eventually(servicesStatus, 5).Should(HaveLen(1))
+	})
+})
@@ -5,7 +5,10 @@ import (
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/internal/resource"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
 )
 
 func (r *RabbitmqClusterReconciler) setDefaultUserStatus(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
@@ -54,3 +57,108 @@ func (r *RabbitmqClusterReconciler) setBinding(ctx context.Context, rmq *rabbitm
 	}
 	return nil
 }
+
+// Status.Image reports the image of the RabbitMQ container of the StatefulSet,
+// after applying the default image and the image registry rewrites of the operator.
+func (r *RabbitmqClusterReconciler) setImage(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	sts, err := r.statefulSet(ctx, rmq)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	var image string
+	for _, c := range sts.Spec.Template.Spec.Containers {
+		if c.Name == "rabbitmq" {
+			image = c.Image
+		}
+	}
+	if rmq.Status.Image != image {
+		rmq.Status.Image = image
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RabbitmqClusterReconciler) setManagementURL(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	url := resource.ManagementURL(rmq)
+	if rmq.Status.ManagementURL != url {
+		rmq.Status.ManagementURL = url
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status.Endpoints lists the URLs of the listeners of the client Service.
+// The ports are taken from the Service, so that ports changed through the Service override are reported correctly.
+func (r *RabbitmqClusterReconciler) setEndpoints(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	service := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: rmq.ChildResourceName(resource.ServiceSuffix)}, service); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var endpoints []rabbitmqv1beta1.RabbitmqClusterEndpoint
+	for _, port := range service.Spec.Ports {
+		internal, ok := resource.ListenerURL(rmq, port.Name, resource.ServiceHost(rmq), port.Port)
+		if !ok {
+			continue
+		}
+		endpoint := rabbitmqv1beta1.RabbitmqClusterEndpoint{
+			Listener: port.Name,
+			Internal: internal,
+		}
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			for _, ingress := range service.Status.LoadBalancer.Ingress {
+				host := ingress.Hostname
+				if host == "" {
+					host = ingress.IP
+				}
+				if host == "" {
+					continue
+				}
+				external, _ := resource.ListenerURL(rmq, port.Name, host, port.Port)
+				endpoint.External = append(endpoint.External, external)
+			}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Listener < endpoints[j].Listener
+	})
+
+	if !reflect.DeepEqual(rmq.Status.Endpoints, endpoints) {
+		rmq.Status.Endpoints = endpoints
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status.PendingRestart is true from a restart-required change of the server-conf ConfigMap until the StatefulSet rolled out the restart.
+func (r *RabbitmqClusterReconciler) setPendingRestart(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	serverConf, err := r.configMap(ctx, rmq, rmq.ChildResourceName(resource.ServerConfigMapName))
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	sts, err := r.statefulSet(ctx, rmq)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	pendingRestart := false
+	if serverConfigUpdatedAt, ok := serverConf.Annotations[serverConfAnnotation]; ok {
+		stsRestartedAt, ok := sts.Spec.Template.Annotations[stsRestartAnnotation]
+		pendingRestart = !ok || stsRestartedAt <= serverConfigUpdatedAt || statefulSetBeingUpdated(sts)
+	}
+
+	if rmq.Status.PendingRestart != pendingRestart {
+		rmq.Status.PendingRestart = pendingRestart
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
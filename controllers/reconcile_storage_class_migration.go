@@ -0,0 +1,497 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Volume claim templates of a StatefulSet cannot be updated and the StorageClass of a PVC cannot be changed.
+// Therefore, a StorageClass migration replaces the PVCs of one Pod at a time, starting with the Pod with the highest ordinal.
+// The progress is persisted in the status of the RabbitmqCluster, so that an interrupted migration picks up where it left off:
+//  1. DeletingStatefulSet: the StatefulSet is deleted without deleting its Pods and recreated with the new volume claim templates
+//  2. Evacuating: the Pod is drained and its quorum queue and stream replicas are removed
+//  3. ReplacingVolumes: the RabbitMQ node is stopped and removed from the cluster, its PVCs and the Pod are deleted
+//  4. Rejoining: the StatefulSet recreates the Pod and its PVCs; once the fresh node is ready, quorum queue and stream replicas are added to it
+//  5. Syncing: the migration of the next Pod starts once the quorum queue and stream replicas on the node have caught up
+//
+// reconcileStorageClassMigration detects a StorageClass change and recreates the StatefulSet.
+// current is nil if the StatefulSet does not exist.
+// A non-zero duration is returned if the StatefulSet must not be created or updated yet.
+func (r *RabbitmqClusterReconciler) reconcileStorageClassMigration(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, current, desired *appsv1.StatefulSet) (time.Duration, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	migration := rmq.Status.StorageClassMigration
+
+	if migration == nil {
+		if current == nil || rmq.Status.PersistenceExpansionStage != "" {
+			return 0, nil
+		}
+		templates := storageClassChanged(current, desired)
+		if len(templates) == 0 {
+			return 0, r.setDeferredAction(ctx, rmq, rabbitmqv1beta1.DeferredStorageClassMigration, false)
+		}
+		if *rmq.Spec.Replicas < 2 {
+			msg := "changing the StorageClass of a single replica RabbitmqCluster is not supported"
+			logger.Error(errors.New("unsupported operation"), msg, "templates", templates)
+			r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", msg)
+			return 0, errors.New(msg)
+		}
+		// outside maintenance windows, the StatefulSet is updated with its current volume claim templates
+		if deferred, err := r.deferDisruptiveAction(ctx, rmq, rabbitmqv1beta1.DeferredStorageClassMigration); err != nil || deferred {
+			return 0, err
+		}
+
+		logger.Info("migrating PersistentVolumeClaims to new StorageClass", "templates", templates)
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "StorageClassMigration",
+			fmt.Sprintf("migrating PersistentVolumeClaims %s to new StorageClass", strings.Join(templates, ", ")))
+		if err := r.updateStorageClassMigration(ctx, rmq, &rabbitmqv1beta1.RabbitmqClusterStorageClassMigrationStatus{
+			VolumeClaimTemplates: templates,
+			Stage:                rabbitmqv1beta1.StorageClassMigrationDeletingStatefulSet,
+			Replicas:             *current.Spec.Replicas,
+		}); err != nil {
+			return 0, err
+		}
+		if err := r.deleteSts(ctx, rmq, current); err != nil {
+			return 0, err
+		}
+		return 2 * time.Second, nil
+	}
+
+	if migration.Stage != rabbitmqv1beta1.StorageClassMigrationDeletingStatefulSet {
+		return 0, nil
+	}
+	if current != nil {
+		if current.DeletionTimestamp.IsZero() {
+			if err := r.deleteSts(ctx, rmq, current); err != nil {
+				return 0, err
+			}
+		}
+		logger.Info("waiting for statefulSet to be deleted before migrating PersistentVolumeClaims", "statefulSet", current.Name)
+		return 2 * time.Second, nil
+	}
+	migration.Stage = rabbitmqv1beta1.StorageClassMigrationEvacuating
+	migration.Pod = serverPodName(rmq, int(migratedReplicas(rmq, migration))-1)
+	return 0, r.updateStorageClassMigration(ctx, rmq, migration)
+}
+
+// migrateStorageClass migrates the PVCs of the Pod recorded in status once the StatefulSet has been recreated.
+// A non-zero duration is returned while the migration is in progress.
+func (r *RabbitmqClusterReconciler) migrateStorageClass(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) (time.Duration, error) {
+	logger := ctrl.LoggerFrom(ctx)
+	migration := rmq.Status.StorageClassMigration
+	if migration == nil || migration.Stage == rabbitmqv1beta1.StorageClassMigrationDeletingStatefulSet {
+		return 0, nil
+	}
+
+	sts, err := r.statefulSet(ctx, rmq)
+	if err != nil {
+		return 0, err
+	}
+	stale, pending, err := r.stalePVCs(ctx, rmq, sts, migration)
+	if err != nil {
+		return 0, err
+	}
+	podName := migration.Pod
+	node := rabbitmqNodeName(rmq, podName)
+
+	switch migration.Stage {
+	case rabbitmqv1beta1.StorageClassMigrationEvacuating:
+		if !allReplicasReadyAndUpdated(sts) {
+			logger.Info("not all replicas ready yet; requeuing request to migrate StorageClass", "pod", podName)
+			return 10 * time.Second, nil
+		}
+		if len(stale) == 0 {
+			// PVCs which do not exist yet will be created by the StatefulSet with the new StorageClass
+			return 0, r.completePodStorageClassMigration(ctx, rmq)
+		}
+		if err := r.runStorageClassMigrationCommand(ctx, rmq, podName, "rabbitmq-upgrade drain"); err != nil {
+			return 0, err
+		}
+		if err := r.runStorageClassMigrationCommand(ctx, rmq, peerPodName(rmq, podName),
+			fmt.Sprintf("set -eo pipefail; rabbitmq-queues shrink %s; %s", node, streamReplicasCommand("delete_replica", node))); err != nil {
+			return 0, err
+		}
+		logger.Info("evacuated RabbitMQ node", "pod", podName)
+		migration.Stage = rabbitmqv1beta1.StorageClassMigrationReplacingVolumes
+		return time.Second, r.updateStorageClassMigration(ctx, rmq, migration)
+
+	case rabbitmqv1beta1.StorageClassMigrationReplacingVolumes:
+		if len(stale) > 0 {
+			pod, err := r.pod(ctx, rmq, podName)
+			if err != nil {
+				return 0, err
+			}
+			if pod != nil && pod.DeletionTimestamp.IsZero() {
+				if err := r.runStorageClassMigrationCommand(ctx, rmq, podName, "rabbitmqctl stop_app"); err != nil {
+					return 0, err
+				}
+			}
+			if err := r.runStorageClassMigrationCommand(ctx, rmq, peerPodName(rmq, podName), forgetClusterNodeCommand(node)); err != nil {
+				return 0, err
+			}
+			for i := range stale {
+				if err := r.deleteMigratedObject(ctx, rmq, &stale[i]); err != nil {
+					return 0, err
+				}
+			}
+			if pod != nil {
+				if err := r.deleteMigratedObject(ctx, rmq, pod); err != nil {
+					return 0, err
+				}
+			}
+		}
+		logger.Info("deleted PersistentVolumeClaims and Pod", "pod", podName)
+		migration.Stage = rabbitmqv1beta1.StorageClassMigrationRejoining
+		return 5 * time.Second, r.updateStorageClassMigration(ctx, rmq, migration)
+
+	case rabbitmqv1beta1.StorageClassMigrationRejoining:
+		pod, err := r.pod(ctx, rmq, podName)
+		if err != nil {
+			return 0, err
+		}
+		if len(stale) > 0 {
+			migration.Stage = rabbitmqv1beta1.StorageClassMigrationReplacingVolumes
+			return time.Second, r.updateStorageClassMigration(ctx, rmq, migration)
+		}
+		if pending {
+			// A Pod referencing a PVC which is being deleted prevents the deletion of the PVC, and
+			// the StatefulSet does not create missing PVCs for existing Pods.
+			// Both happen when the StatefulSet recreates the Pod before the PVC is gone.
+			if pod != nil && pod.DeletionTimestamp.IsZero() {
+				if err := r.deleteMigratedObject(ctx, rmq, pod); err != nil {
+					return 0, err
+				}
+			}
+			logger.Info("waiting for PersistentVolumeClaims to be recreated", "pod", podName)
+			return 5 * time.Second, nil
+		}
+		if pod == nil || !podReady(pod) {
+			logger.Info("waiting for Pod to rejoin the cluster", "pod", podName)
+			return 10 * time.Second, nil
+		}
+		if err := r.runStorageClassMigrationCommand(ctx, rmq, podName, growReplicasCommand(node)); err != nil {
+			return 0, err
+		}
+		logger.Info("added quorum queue and stream replicas to RabbitMQ node", "pod", podName)
+		migration.Stage = rabbitmqv1beta1.StorageClassMigrationSyncing
+		return time.Second, r.updateStorageClassMigration(ctx, rmq, migration)
+
+	case rabbitmqv1beta1.StorageClassMigrationSyncing:
+		if !r.replicasSynchronised(ctx, rmq, podName) {
+			return 10 * time.Second, nil
+		}
+		return 0, r.completePodStorageClassMigration(ctx, rmq)
+	}
+	return 0, nil
+}
+
+// completePodStorageClassMigration records the current Pod as migrated and moves on to the Pod with the next lower ordinal.
+// The migration status is cleared once all Pods have been migrated.
+func (r *RabbitmqClusterReconciler) completePodStorageClassMigration(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	migration := rmq.Status.StorageClassMigration
+	r.Recorder.Event(rmq, corev1.EventTypeNormal, "StorageClassMigration", fmt.Sprintf("migrated pod %s", migration.Pod))
+	migration.MigratedPods = append(migration.MigratedPods, migration.Pod)
+
+	next := int(migratedReplicas(rmq, migration)) - 1 - len(migration.MigratedPods)
+	if next < 0 {
+		ctrl.LoggerFrom(ctx).Info("successfully migrated PersistentVolumeClaims to new StorageClass")
+		r.Recorder.Event(rmq, corev1.EventTypeNormal, "SuccessfulStorageClassMigration", "migrated PersistentVolumeClaims to new StorageClass")
+		return r.updateStorageClassMigration(ctx, rmq, nil)
+	}
+	migration.Pod = serverPodName(rmq, next)
+	migration.Stage = rabbitmqv1beta1.StorageClassMigrationEvacuating
+	return r.updateStorageClassMigration(ctx, rmq, migration)
+}
+
+// migratedReplicas returns the number of replicas recorded when the migration started.
+// Migrations started by earlier versions of the operator did not record it.
+func migratedReplicas(rmq *rabbitmqv1beta1.RabbitmqCluster, migration *rabbitmqv1beta1.RabbitmqClusterStorageClassMigrationStatus) int32 {
+	if migration.Replicas == 0 {
+		return *rmq.Spec.Replicas
+	}
+	return migration.Replicas
+}
+
+func (r *RabbitmqClusterReconciler) updateStorageClassMigration(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, migration *rabbitmqv1beta1.RabbitmqClusterStorageClassMigrationStatus) error {
+	rmq.Status.StorageClassMigration = migration
+	if err := r.Status().Update(ctx, rmq); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to update StorageClass migration status")
+		return err
+	}
+	return nil
+}
+
+// stalePVCs returns the PVCs of the Pod being migrated which still use a StorageClass other than the one of their volume claim template.
+// pending is true if any PVC does not exist or is being deleted.
+func (r *RabbitmqClusterReconciler) stalePVCs(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, sts *appsv1.StatefulSet, migration *rabbitmqv1beta1.RabbitmqClusterStorageClassMigrationStatus) (stale []corev1.PersistentVolumeClaim, pending bool, err error) {
+	for _, t := range sts.Spec.VolumeClaimTemplates {
+		if !containsString(migration.VolumeClaimTemplates, t.Name) {
+			continue
+		}
+		desiredStorageClass, err := r.desiredStorageClassName(ctx, t)
+		if err != nil {
+			return nil, false, err
+		}
+
+		PVCName := fmt.Sprintf("%s-%s", t.Name, migration.Pod)
+		PVC := corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: PVCName}, &PVC); err != nil {
+			if k8serrors.IsNotFound(err) {
+				pending = true
+				continue
+			}
+			return nil, false, err
+		}
+		if !PVC.DeletionTimestamp.IsZero() {
+			pending = true
+			continue
+		}
+		if PVC.Spec.StorageClassName == nil || *PVC.Spec.StorageClassName != desiredStorageClass {
+			stale = append(stale, PVC)
+		}
+	}
+	return stale, pending, nil
+}
+
+// desiredStorageClassName returns the StorageClass of PVCs created from the given volume claim template
+func (r *RabbitmqClusterReconciler) desiredStorageClassName(ctx context.Context, template corev1.PersistentVolumeClaim) (string, error) {
+	if template.Spec.StorageClassName != nil {
+		return *template.Spec.StorageClassName, nil
+	}
+	return r.defaultStorageClassName(ctx)
+}
+
+func (r *RabbitmqClusterReconciler) pod(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, name string) (*corev1.Pod, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rmq.Namespace, Name: name}, pod); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (r *RabbitmqClusterReconciler) deleteMigratedObject(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, obj client.Object) error {
+	logger := ctrl.LoggerFrom(ctx)
+	if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+		msg := fmt.Sprintf("failed to delete %T", obj)
+		logger.Error(err, msg, "name", obj.GetName())
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, obj.GetName()))
+		return fmt.Errorf("%s %s: %v", msg, obj.GetName(), err)
+	}
+	return nil
+}
+
+func (r *RabbitmqClusterReconciler) runStorageClassMigrationCommand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName, cmd string) error {
+	return r.runPersistenceCommand(ctx, rmq, podName, cmd, "failed to migrate StorageClass on pod")
+}
+
+func (r *RabbitmqClusterReconciler) runPersistenceCommand(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName, cmd, msg string) error {
+	logger := ctrl.LoggerFrom(ctx)
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "bash", "-c", cmd)
+	if err != nil {
+		logger.Error(err, msg, "pod", podName, "command", cmd, "stdout", stdout, "stderr", stderr)
+		r.Recorder.Event(rmq, corev1.EventTypeWarning, "FailedReconcilePersistence", fmt.Sprintf("%s %s", msg, podName))
+		return fmt.Errorf("%s %s: %v", msg, podName, err)
+	}
+	return nil
+}
+
+// storageClassChanged returns the names of the volume claim templates whose StorageClass differs between current and desired
+func storageClassChanged(current, desired *appsv1.StatefulSet) []string {
+	currentStorageClasses := map[string]string{}
+	for _, t := range current.Spec.VolumeClaimTemplates {
+		currentStorageClasses[t.Name] = storageClassNameOf(t)
+	}
+	var changed []string
+	for _, t := range desired.Spec.VolumeClaimTemplates {
+		if currentStorageClass, ok := currentStorageClasses[t.Name]; ok && currentStorageClass != storageClassNameOf(t) {
+			changed = append(changed, t.Name)
+		}
+	}
+	return changed
+}
+
+func storageClassNameOf(template corev1.PersistentVolumeClaim) string {
+	if template.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *template.Spec.StorageClassName
+}
+
+// replicaStatusCommand prints the status of the replicas of every quorum queue and stream, one queue per line:
+// the type of the queue, the vhost and name of the queue, and the output of quorum_status or stream_status, separated by tabs.
+// A queue whose status cannot be retrieved is printed without status.
+const replicaStatusCommand = "rabbitmqctl -q list_vhosts --no-table-headers | while read -r vhost; do " +
+	"rabbitmqctl -q list_queues -p \"$vhost\" --no-table-headers name type < /dev/null | while IFS=$'\\t' read -r queue type; do " +
+	"case \"$type\" in " +
+	"quorum) printf 'quorum\\t%s/%s\\t' \"$vhost\" \"$queue\"; rabbitmq-queues -q quorum_status -p \"$vhost\" \"$queue\" --formatter json < /dev/null | tr -d '\\n'; echo;; " +
+	"stream) printf 'stream\\t%s/%s\\t' \"$vhost\" \"$queue\"; rabbitmq-streams -q stream_status -p \"$vhost\" \"$queue\" --formatter json < /dev/null | tr -d '\\n'; echo;; " +
+	"esac; done; done"
+
+// quorumMember is an entry of the output of 'rabbitmq-queues quorum_status --formatter json'
+type quorumMember struct {
+	Node        string `json:"Node Name"`
+	RaftState   string `json:"Raft State"`
+	LogIndex    int64  `json:"Log Index"`
+	CommitIndex int64  `json:"Commit Index"`
+}
+
+// streamMember is an entry of the output of 'rabbitmq-streams stream_status --formatter json'
+type streamMember struct {
+	Node            string `json:"node"`
+	Role            string `json:"role"`
+	Offset          int64  `json:"offset"`
+	CommittedOffset int64  `json:"committed_offset"`
+}
+
+// replicasSynchronised returns true once the quorum queue and stream replicas on the node of the Pod have caught up:
+// the log of each quorum queue member reaches the commit index of the leader, and each stream replica reaches the committed offset of the writer.
+// Queues which have no replica on the node are ignored.
+func (r *RabbitmqClusterReconciler) replicasSynchronised(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) bool {
+	logger := ctrl.LoggerFrom(ctx)
+	stdout, stderr, err := r.exec(rmq.Namespace, podName, "rabbitmq", "bash", "-c", replicaStatusCommand)
+	if err != nil {
+		logger.Info("waiting for queue replicas to be synchronised", "pod", podName, "stdout", stdout, "stderr", stderr)
+		return false
+	}
+	queue, err := unsynchronisedReplica(stdout, rabbitmqNodeName(rmq, podName))
+	if err != nil {
+		logger.Error(err, "failed to parse the status of queue replicas", "pod", podName, "stdout", stdout)
+		return false
+	}
+	if queue != "" {
+		logger.Info("waiting for queue replicas to be synchronised", "pod", podName, "queue", queue)
+		return false
+	}
+	return true
+}
+
+// unsynchronisedReplica returns the first queue in the output of replicaStatusCommand whose replica on the node has not caught up,
+// or an empty string if all replicas on the node have caught up
+func unsynchronisedReplica(output, node string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			return "", fmt.Errorf("unexpected replica status: %s", line)
+		}
+		queueType, queue, status := fields[0], fields[1], fields[2]
+		if strings.TrimSpace(status) == "" {
+			return queue, nil
+		}
+
+		switch queueType {
+		case "quorum":
+			var members []quorumMember
+			if err := json.Unmarshal([]byte(status), &members); err != nil {
+				return "", fmt.Errorf("failed to parse status of quorum queue %s: %w", queue, err)
+			}
+			var leader, replica *quorumMember
+			for i := range members {
+				if members[i].RaftState == "leader" {
+					leader = &members[i]
+				}
+				if members[i].Node == node {
+					replica = &members[i]
+				}
+			}
+			if replica != nil && (leader == nil || replica.LogIndex < leader.CommitIndex) {
+				return queue, nil
+			}
+		case "stream":
+			var members []streamMember
+			if err := json.Unmarshal([]byte(status), &members); err != nil {
+				return "", fmt.Errorf("failed to parse status of stream %s: %w", queue, err)
+			}
+			var writer, replica *streamMember
+			for i := range members {
+				if members[i].Role == "writer" {
+					writer = &members[i]
+				}
+				if members[i].Node == node {
+					replica = &members[i]
+				}
+			}
+			if replica != nil && (writer == nil || replica.Offset < writer.CommittedOffset) {
+				return queue, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// forgetClusterNodeCommand removes the given node from the cluster if the cluster still lists it
+func forgetClusterNodeCommand(node string) string {
+	return fmt.Sprintf("if rabbitmqctl -q cluster_status --formatter json | grep -qF '\"%s\"'; then rabbitmqctl forget_cluster_node %s; fi", node, node)
+}
+
+// growReplicasCommand adds the given node to all quorum queues and streams
+func growReplicasCommand(node string) string {
+	return fmt.Sprintf("set -eo pipefail; rabbitmq-queues grow %s all; %s", node, streamReplicasCommand("add_replica", node))
+}
+
+// streamReplicasCommand returns a command which runs 'rabbitmq-streams <action>' (add_replica or delete_replica)
+// for the given node on every stream the node is not a member of (add_replica) or is a member of (delete_replica).
+// It does nothing on RabbitMQ versions without streams.
+func streamReplicasCommand(action, node string) string {
+	condition := ""
+	if action == "add_replica" {
+		condition = "! "
+	}
+	return "if command -v rabbitmq-streams > /dev/null; then " +
+		"rabbitmqctl -q list_vhosts --no-table-headers | while read -r vhost; do " +
+		"rabbitmqctl -q list_queues -p \"$vhost\" --no-table-headers name type < /dev/null | awk -F '\\t' '$2 == \"stream\" {print $1}' | while read -r stream; do " +
+		fmt.Sprintf("if %srabbitmq-streams stream_status -p \"$vhost\" \"$stream\" --formatter json < /dev/null | grep -qF '\"%s\"'; then ", condition, node) +
+		fmt.Sprintf("rabbitmq-streams %s -p \"$vhost\" \"$stream\" %s < /dev/null; fi; ", action, node) +
+		"done; done; fi"
+}
+
+func serverPodName(rmq *rabbitmqv1beta1.RabbitmqCluster, i int) string {
+	return fmt.Sprintf("%s-%d", rmq.ChildResourceName("server"), i)
+}
+
+// peerPodName returns a Pod other than the given one to run commands which must not run on the node being migrated
+func peerPodName(rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) string {
+	if podName == serverPodName(rmq, 0) {
+		return serverPodName(rmq, 1)
+	}
+	return serverPodName(rmq, 0)
+}
+
+func rabbitmqNodeName(rmq *rabbitmqv1beta1.RabbitmqCluster, podName string) string {
+	return fmt.Sprintf("rabbit@%s.%s.%s", podName, rmq.ChildResourceName(resource.HeadlessServiceSuffix), rmq.Namespace)
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package controllers_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("StorageClass migration", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		defaultNamespace = "default"
+		ctx              = context.Background()
+		oldStorageClass  = "rabbitmq-migration-old"
+		newStorageClass  = "rabbitmq-migration-new"
+	)
+
+	migrationStatus := func() *rabbitmqv1beta1.RabbitmqClusterStorageClassMigrationStatus {
+		rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+		Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+		return rmq.Status.StorageClassMigration
+	}
+
+	migrationStage := func() rabbitmqv1beta1.StorageClassMigrationStage {
+		if m := migrationStatus(); m != nil {
+			return m.Stage
+		}
+		return ""
+	}
+
+	// envtest does not run the StatefulSet controller
+	createPVC := func(podIndex, storageClass string) {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "persistence-" + cluster.ChildResourceName("server") + "-" + podIndex,
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: pointer.StringPtr(storageClass),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse("10Gi")},
+				},
+			},
+		}
+		Expect(client.Create(ctx, pvc)).To(Succeed())
+	}
+
+	createReadyPod := func(podIndex string) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.ChildResourceName("server") + "-" + podIndex,
+				Namespace: defaultNamespace,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+			},
+		}
+		Expect(client.Create(ctx, pod)).To(Succeed())
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		Expect(client.Status().Update(ctx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-storage-class-migration",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(2),
+				Persistence: rabbitmqv1beta1.RabbitmqClusterPersistenceSpec{
+					StorageClassName: pointer.StringPtr(oldStorageClass),
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		Expect(runtimeClient.IgnoreNotFound(client.Delete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name:      "persistence-" + cluster.ChildResourceName("server") + "-1",
+			Namespace: defaultNamespace,
+		}}))).To(Succeed())
+		Expect(runtimeClient.IgnoreNotFound(client.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.ChildResourceName("server") + "-1",
+			Namespace: defaultNamespace,
+		}}))).To(Succeed())
+	})
+
+	It("replaces the PVCs one Pod at a time and tracks the progress in status", func() {
+		createPVC("1", oldStorageClass)
+		createReadyPod("1")
+
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Persistence.StorageClassName = pointer.StringPtr(newStorageClass)
+		})).To(Succeed())
+
+		By("recreating the StatefulSet with the new StorageClass", func() {
+			Eventually(migrationStage, 5).Should(Equal(rabbitmqv1beta1.StorageClassMigrationDeletingStatefulSet))
+			Expect(migrationStatus().VolumeClaimTemplates).To(ConsistOf("persistence"))
+			Expect(migrationStatus().Replicas).To(Equal(int32(2)))
+			Eventually(func() bool {
+				return statefulSet(ctx, cluster).DeletionTimestamp.IsZero()
+			}, 5).Should(BeFalse())
+
+			// envtest does not run the garbage collector which removes the orphan finalizer
+			sts := statefulSet(ctx, cluster)
+			sts.Finalizers = nil
+			Expect(client.Update(ctx, sts)).To(Succeed())
+
+			Eventually(func() string {
+				sts := &appsv1.StatefulSet{}
+				if err := client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server"), Namespace: defaultNamespace}, sts); err != nil {
+					return ""
+				}
+				return *sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName
+			}, 5).Should(Equal(newStorageClass))
+			Expect(migrationStage()).To(Equal(rabbitmqv1beta1.StorageClassMigrationEvacuating))
+			Expect(migrationStatus().Pod).To(Equal(cluster.ChildResourceName("server") + "-1"))
+		})
+
+		By("evacuating and removing the node of the Pod with the highest ordinal", func() {
+			sts := statefulSet(ctx, cluster)
+			sts.Status.Replicas = 2
+			sts.Status.ReadyReplicas = 2
+			Expect(client.Status().Update(ctx, sts)).To(Succeed())
+
+			Eventually(migrationStage, 10).Should(Equal(rabbitmqv1beta1.StorageClassMigrationRejoining))
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElements(
+				command{"bash", "-c", "rabbitmq-upgrade drain"},
+				command{"bash", "-c", "rabbitmqctl stop_app"},
+			))
+			Eventually(func() error {
+				return client.Get(ctx, types.NamespacedName{Name: cluster.ChildResourceName("server") + "-1", Namespace: defaultNamespace}, &corev1.Pod{})
+			}, 5).ShouldNot(Succeed())
+		})
+
+		By("adding replicas to the fresh node once it is ready", func() {
+			createPVC("1", newStorageClass)
+			createReadyPod("1")
+
+			Eventually(migrationStatus, 30).Should(BeNil())
+			// the replicas of the fresh node are compared with their leaders
+			Expect(fakeExecutor.ExecutedCommands()).To(ContainElement(WithTransform(func(c command) string {
+				return strings.Join(c, " ")
+			}, ContainSubstring("rabbitmq-queues -q quorum_status"))))
+			Expect(aggregateEventMsgs(ctx, cluster, "SuccessfulStorageClassMigration")).To(
+				ContainSubstring("migrated PersistentVolumeClaims to new StorageClass"))
+		})
+	})
+})
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -77,3 +79,41 @@ func (r *RabbitmqClusterReconciler) checkTLSSecrets(ctx context.Context, rabbitm
 	}
 	return nil
 }
+
+// reconcileBindingCA copies the CA certificate into the default user Secret, so that applications bound to the cluster can verify the server certificate.
+// The CA certificate is taken from the CA Secret if mutual TLS is configured, otherwise from the ca.crt key of the TLS Secret, if present.
+func (r *RabbitmqClusterReconciler) reconcileBindingCA(ctx context.Context, rabbitmqCluster *rabbitmqv1beta1.RabbitmqCluster) error {
+	var caCert []byte
+	if rabbitmqCluster.TLSEnabled() {
+		secretName := rabbitmqCluster.Spec.TLS.SecretName
+		if rabbitmqCluster.MutualTLSEnabled() {
+			secretName = rabbitmqCluster.Spec.TLS.CaSecretName
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rabbitmqCluster.Namespace, Name: secretName}, secret); err != nil {
+			return fmt.Errorf("failed to get TLS secret %s: %w", secretName, err)
+		}
+		caCert = secret.Data["ca.crt"]
+	}
+
+	binding := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rabbitmqCluster.Namespace, Name: rabbitmqCluster.ChildResourceName(resource.DefaultUserSecretName)}, binding); err != nil {
+		return err
+	}
+	current, exists := binding.Data["ca.crt"]
+	if bytes.Equal(current, caCert) && exists == (caCert != nil) {
+		return nil
+	}
+	if caCert == nil {
+		delete(binding.Data, "ca.crt")
+	} else {
+		if binding.Data == nil {
+			binding.Data = map[string][]byte{}
+		}
+		binding.Data["ca.crt"] = caCert
+	}
+	if err := r.Update(ctx, binding); err != nil {
+		return fmt.Errorf("failed to update CA certificate of Secret %s: %w", binding.Name, err)
+	}
+	return nil
+}
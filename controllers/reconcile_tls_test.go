@@ -71,6 +71,26 @@ var _ = Describe("Reconcile TLS", func() {
 				}))
 			})
 
+			It("adds the CA certificate and TLS connection details to the binding Secret", func() {
+				tlsSecretWithCACert(ctx, "tls-secret", defaultNamespace)
+				tlsSpec := rabbitmqv1beta1.TLSSpec{
+					SecretName:   "tls-secret",
+					CaSecretName: "tls-secret",
+				}
+				cluster = rabbitmqClusterWithTLS(ctx, "mutual-tls-binding", defaultNamespace, tlsSpec)
+				waitForClusterCreation(ctx, cluster, client)
+
+				Eventually(func() map[string][]byte {
+					secret, err := clientSet.CoreV1().Secrets(cluster.Namespace).Get(ctx, cluster.ChildResourceName("default-user"), metav1.GetOptions{})
+					Expect(err).NotTo(HaveOccurred())
+					return secret.Data
+				}, 5).Should(SatisfyAll(
+					HaveKeyWithValue("ca.crt", []byte("certificate")),
+					HaveKeyWithValue("tls", []byte("true")),
+					HaveKeyWithValue("port", []byte("5671")),
+				))
+			})
+
 			It("Does not deploy if the cert name does not match the contents of the secret", func() {
 				tlsData := map[string]string{
 					"tls.crt":   "this is a tls cert",
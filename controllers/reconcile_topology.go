@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// setZones copies the zone label of the Kubernetes node of each scheduled Pod to the Pod, and records the zones in status.
+// The Pod label is exposed to the RabbitMQ container in /etc/pod-info/zone if spec.topology is set.
+// Pods cannot read the labels of their Kubernetes node through the downward API.
+func (r *RabbitmqClusterReconciler) setZones(ctx context.Context, rmq *rabbitmqv1beta1.RabbitmqCluster) error {
+	logger := ctrl.LoggerFrom(ctx)
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(rmq.Namespace), client.MatchingLabels(metadata.LabelSelector(rmq.Name))); err != nil {
+		return fmt.Errorf("failed to list Pods: %w", err)
+	}
+
+	var zones []rabbitmqv1beta1.RabbitmqClusterNodeZone
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			return fmt.Errorf("failed to get node %s of Pod %s: %w", pod.Spec.NodeName, pod.Name, err)
+		}
+		zone := node.Labels[resource.ZoneLabel]
+		zones = append(zones, rabbitmqv1beta1.RabbitmqClusterNodeZone{
+			Pod:  pod.Name,
+			Node: node.Name,
+			Zone: zone,
+		})
+
+		if zone != "" && pod.Labels[resource.ZoneLabel] != zone {
+			if pod.Labels == nil {
+				pod.Labels = map[string]string{}
+			}
+			pod.Labels[resource.ZoneLabel] = zone
+			if err := r.Update(ctx, pod); err != nil {
+				return fmt.Errorf("failed to label Pod %s with zone %s: %w", pod.Name, zone, err)
+			}
+			logger.Info("labelled Pod with zone", "pod", pod.Name, "zone", zone)
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].Pod < zones[j].Pod
+	})
+
+	if !reflect.DeepEqual(rmq.Status.Zones, zones) {
+		rmq.Status.Zones = zones
+		if err := r.Status().Update(ctx, rmq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Zones", func() {
+	var (
+		cluster          *rabbitmqv1beta1.RabbitmqCluster
+		node             *corev1.Node
+		pod              *corev1.Pod
+		defaultNamespace = "default"
+		ctx              = context.Background()
+	)
+
+	BeforeEach(func() {
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-in-zone-a",
+				Labels: map[string]string{"topology.kubernetes.io/zone": "zone-a"},
+			},
+		}
+		Expect(client.Create(ctx, node)).To(Succeed())
+
+		cluster = &rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rabbitmq-zones",
+				Namespace: defaultNamespace,
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(1),
+				Topology: &rabbitmqv1beta1.RabbitmqClusterTopologySpec{
+					Zones: &rabbitmqv1beta1.TopologySpreadSpec{},
+				},
+			},
+		}
+		Expect(client.Create(ctx, cluster)).To(Succeed())
+		waitForClusterCreation(ctx, cluster, client)
+	})
+
+	AfterEach(func() {
+		Expect(client.Delete(ctx, cluster)).To(Succeed())
+		waitForClusterDeletion(ctx, cluster, client)
+		Expect(client.Delete(ctx, pod)).To(Succeed())
+		Expect(client.Delete(ctx, node)).To(Succeed())
+	})
+
+	It("labels the Pods with the zone of their node and reports the zones in status", func() {
+		// envtest does not run the StatefulSet controller and scheduler
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cluster.ChildResourceName("server") + "-0",
+				Namespace: defaultNamespace,
+				Labels:    map[string]string{"app.kubernetes.io/name": cluster.Name},
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   node.Name,
+				Containers: []corev1.Container{{Name: "rabbitmq", Image: "rabbitmq"}},
+			},
+		}
+		Expect(client.Create(ctx, pod)).To(Succeed())
+
+		// trigger a reconcile
+		Expect(updateWithRetry(cluster, func(r *rabbitmqv1beta1.RabbitmqCluster) {
+			r.Spec.Topology.Hosts = &rabbitmqv1beta1.TopologySpreadSpec{}
+		})).To(Succeed())
+
+		Eventually(func() []rabbitmqv1beta1.RabbitmqClusterNodeZone {
+			rmq := &rabbitmqv1beta1.RabbitmqCluster{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, rmq)).To(Succeed())
+			return rmq.Status.Zones
+		}, 5).Should(ConsistOf(rabbitmqv1beta1.RabbitmqClusterNodeZone{
+			Pod:  pod.Name,
+			Node: node.Name,
+			Zone: "zone-a",
+		}))
+
+		Expect(client.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: defaultNamespace}, pod)).To(Succeed())
+		Expect(pod.Labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "zone-a"))
+	})
+})
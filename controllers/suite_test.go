@@ -13,7 +13,9 @@ package controllers_test
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/client-go/util/retry"
 
@@ -33,7 +35,11 @@ import (
 	// +kubebuilder:scaffold:imports
 )
 
-const controllerName = "rabbitmqcluster-controller"
+const (
+	controllerName = "rabbitmqcluster-controller"
+	// default of spec.image in the CRD
+	defaultRabbitmqImage = "rabbitmq:3.8.16-management"
+)
 
 var (
 	testEnv         *envtest.Environment
@@ -87,6 +93,9 @@ var _ = BeforeSuite(func() {
 		Recorder:    mgr.GetEventRecorderFor(controllerName),
 		Namespace:   "rabbitmq-system",
 		PodExecutor: fakeExecutor,
+
+		DefaultRabbitmqImage:       defaultRabbitmqImage,
+		NodesStatusRefreshInterval: time.Minute,
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -106,17 +115,31 @@ var _ = AfterSuite(func() {
 
 type fakePodExecutor struct {
 	executedCommands []command
+	stdout           map[string]string
 }
 
 type command []string
 
 func (f *fakePodExecutor) Exec(clientset *kubernetes.Clientset, clusterConfig *rest.Config, namespace, podName, containerName string, command ...string) (string, string, error) {
 	f.executedCommands = append(f.executedCommands, command)
-	return "", "", nil
+	return f.stdout[strings.Join(command, " ")], "", nil
 }
 
 func (f *fakePodExecutor) ExecutedCommands() []command { return f.executedCommands }
 
 func (f *fakePodExecutor) ResetExecutedCommands() { f.executedCommands = []command{} }
 
-var _ = AfterEach(func() { fakeExecutor.ResetExecutedCommands() })
+// SetStdout makes the fake executor return stdout whenever the given command is executed.
+func (f *fakePodExecutor) SetStdout(cmd command, stdout string) {
+	if f.stdout == nil {
+		f.stdout = map[string]string{}
+	}
+	f.stdout[strings.Join(cmd, " ")] = stdout
+}
+
+func (f *fakePodExecutor) ResetStdout() { f.stdout = map[string]string{} }
+
+var _ = AfterEach(func() {
+	fakeExecutor.ResetExecutedCommands()
+	fakeExecutor.ResetStdout()
+})
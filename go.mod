@@ -16,7 +16,9 @@ require (
 	github.com/rabbitmq/rabbitmq-stream-go-client v0.0.0-20210422170636-520637be5dde
 	github.com/sclevine/yj v0.0.0-20200815061347-554173e71934
 	github.com/streadway/amqp v1.0.0
+	go.uber.org/zap v1.15.0
 	golang.org/x/net v0.0.0-20210428140749-89ef3d95e781
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 	gopkg.in/ini.v1 v1.62.0
 	k8s.io/api v0.20.2
 	k8s.io/apimachinery v0.20.5
@@ -26,4 +28,5 @@ require (
 	sigs.k8s.io/controller-tools v0.5.0
 	sigs.k8s.io/kind v0.11.1
 	sigs.k8s.io/kustomize/kustomize/v3 v3.10.0
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,127 @@
+#!/bin/bash
+
+# Builds an installation manifest of an operator which watches only the given namespaces.
+# The operator ClusterRole is bound with a RoleBinding in each watched namespace instead of a ClusterRoleBinding,
+# so that the operator cannot access Secrets and other resources of any other namespace.
+#
+# Usage:
+#   generate-namespaced-installation-manifest.sh tenant-a,tenant-b
+#   generate-namespaced-installation-manifest.sh --selector rabbitmq.com/tenant=true
+#
+# With --selector, the namespaces matching the selector in the cluster of the current kubectl context are bound.
+# The operator selects its namespaces when it starts; re-run this script and restart the operator
+# after labelling a new namespace.
+#
+# Requires kustomize and yq v4.
+
+set -euo pipefail
+
+SCRIPT_DIR="$(dirname "$0")"
+OPERATOR_NAMESPACE=rabbitmq-system
+OPERATOR_NAME=rabbitmq-cluster-operator
+
+usage() {
+  echo "Usage: $0 <namespace>[,<namespace>...] | --selector <label selector>"
+  exit 1
+}
+
+if [[ $# -eq 1 ]]
+then
+  NAMESPACES=$1
+  WATCH_CONFIG="watchNamespaces: [$NAMESPACES]"
+elif [[ $# -eq 2 && $1 == "--selector" ]]
+then
+  NAMESPACES=$(kubectl get namespaces --selector "$2" --output jsonpath='{range .items[*]}{.metadata.name},{end}')
+  NAMESPACES=${NAMESPACES%,}
+  WATCH_CONFIG="watchNamespaceSelector: \"$2\""
+  if [[ -z $NAMESPACES ]]
+  then
+    echo "No namespace matches $2"
+    exit 1
+  fi
+else
+  usage
+fi
+
+cat "$SCRIPT_DIR/NOTICE.yaml.txt"
+
+kustomize build "$SCRIPT_DIR/../config/installation/" | \
+  WATCH_CONFIG="$WATCH_CONFIG" yq eval '
+    select(.kind != "ClusterRoleBinding") |
+    (select(.kind == "ConfigMap" and .metadata.name == "rabbitmq-cluster-operator-config") | .data["config.yaml"]) += strenv(WATCH_CONFIG) + "\n"
+  ' -
+
+# StorageClasses, Namespaces and Nodes are cluster scoped and cannot be granted by a RoleBinding
+cat <<EOF
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: $OPERATOR_NAME-cluster-scoped-role
+  labels:
+    app.kubernetes.io/name: $OPERATOR_NAME
+    app.kubernetes.io/component: rabbitmq-operator
+    app.kubernetes.io/part-of: rabbitmq
+rules:
+- apiGroups:
+  - storage.k8s.io
+  resources:
+  - storageclasses
+  verbs:
+  - get
+  - list
+- apiGroups:
+  - ""
+  resources:
+  - namespaces
+  verbs:
+  - list
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  verbs:
+  - get
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: $OPERATOR_NAME-cluster-scoped-rolebinding
+  labels:
+    app.kubernetes.io/name: $OPERATOR_NAME
+    app.kubernetes.io/component: rabbitmq-operator
+    app.kubernetes.io/part-of: rabbitmq
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: $OPERATOR_NAME-cluster-scoped-role
+subjects:
+- kind: ServiceAccount
+  name: $OPERATOR_NAME
+  namespace: $OPERATOR_NAMESPACE
+EOF
+
+IFS=',' read -ra NAMESPACE_LIST <<< "$NAMESPACES"
+for namespace in "${NAMESPACE_LIST[@]}"
+do
+cat <<EOF
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: rabbitmq-cluster-operator-rolebinding
+  namespace: $namespace
+  labels:
+    app.kubernetes.io/name: $OPERATOR_NAME
+    app.kubernetes.io/component: rabbitmq-operator
+    app.kubernetes.io/part-of: rabbitmq
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: rabbitmq-cluster-operator-role
+subjects:
+- kind: ServiceAccount
+  name: $OPERATOR_NAME
+  namespace: $OPERATOR_NAMESPACE
+EOF
+done
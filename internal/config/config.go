@@ -0,0 +1,279 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	APIVersion = "config.rabbitmq.com/v1alpha1"
+	Kind       = "OperatorConfiguration"
+)
+
+// OperatorConfiguration is the configuration file of the operator.
+// It is usually mounted from a ConfigMap and passed to the operator with the --config flag.
+type OperatorConfiguration struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Settings of the RabbitmqCluster controller
+	Controller ControllerConfiguration `json:"controller,omitempty"`
+	// Minimum frequency at which all watched resources are reconciled
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+	// One of: debug, info, error
+	LogLevel string `json:"logLevel,omitempty"`
+	// Address the metrics endpoint binds to. "0" disables the endpoint.
+	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	// Address the health probe endpoints /healthz and /readyz bind to. "0" disables the endpoints.
+	HealthProbeBindAddress string `json:"healthProbeBindAddress,omitempty"`
+	// Images used when not set in the RabbitmqCluster
+	DefaultImages DefaultImages `json:"defaultImages,omitempty"`
+	// Registry settings applied to the images of all Pods created by the operator
+	ImageRegistry ImageRegistryConfiguration `json:"imageRegistry,omitempty"`
+	// Namespaces watched by the operator. All namespaces are watched if neither watchNamespaces nor watchNamespaceSelector is set.
+	// The OPERATOR_SCOPE_NAMESPACE environment variable takes precedence.
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+	// Label selector of the namespaces watched by the operator, e.g. "rabbitmq.com/tenant=true".
+	// Namespaces are selected when the operator starts.
+	WatchNamespaceSelector string `json:"watchNamespaceSelector,omitempty"`
+}
+
+type ControllerConfiguration struct {
+	// Maximum number of RabbitmqClusters reconciled concurrently
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+	// Rate limiter of the work queue of the controller
+	RateLimiter RateLimiterConfiguration `json:"rateLimiter,omitempty"`
+	// Interval at which status.nodes of each RabbitmqCluster is refreshed. "0s" disables status.nodes.
+	NodesStatusRefreshInterval *metav1.Duration `json:"nodesStatusRefreshInterval,omitempty"`
+}
+
+// RateLimiterConfiguration configures the work queue rate limiter, which is the maximum of
+// a per item exponential backoff and an overall token bucket.
+type RateLimiterConfiguration struct {
+	// Requeue delay after the first failure of a reconcile
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// Maximum requeue delay after repeated failures of a reconcile
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+	// Overall number of reconciles per second
+	QPS float64 `json:"qps,omitempty"`
+	// Overall burst of reconciles
+	Burst int `json:"burst,omitempty"`
+}
+
+type DefaultImages struct {
+	// Image of RabbitMQ nodes
+	RabbitMQ string `json:"rabbitmq,omitempty"`
+}
+
+// ImageRegistryConfiguration allows to pull all images from a mirror, e.g. in air-gapped environments.
+type ImageRegistryConfiguration struct {
+	// Image name prefixes to replace. The longest matching prefix is replaced.
+	// Docker Hub images without registry, such as "rabbitmq:3.8.16", match "docker.io/library/".
+	Rewrites []RegistryRewrite `json:"rewrites,omitempty"`
+	// Names of Secrets added to the imagePullSecrets of all Pods.
+	// The Secrets must exist in the namespace of each RabbitmqCluster.
+	PullSecrets []string `json:"pullSecrets,omitempty"`
+}
+
+type RegistryRewrite struct {
+	// Image name prefix to replace, e.g. "docker.io/"
+	From string `json:"from"`
+	// Replacement of the prefix, e.g. "mirror.example.com/dockerhub/"
+	To string `json:"to"`
+}
+
+var logLevels = []string{"debug", "info", "error"}
+
+// Default returns the configuration used when no configuration file is provided.
+// Settings not set in a configuration file are taken from the default configuration.
+// The rate limiter settings are the defaults of client-go's work queue.
+func Default() *OperatorConfiguration {
+	return &OperatorConfiguration{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Controller: ControllerConfiguration{
+			MaxConcurrentReconciles: 1,
+			RateLimiter: RateLimiterConfiguration{
+				BaseDelay: metav1.Duration{Duration: 5 * time.Millisecond},
+				MaxDelay:  metav1.Duration{Duration: 1000 * time.Second},
+				QPS:       10,
+				Burst:     100,
+			},
+			NodesStatusRefreshInterval: &metav1.Duration{Duration: time.Minute},
+		},
+		SyncPeriod:             &metav1.Duration{Duration: 10 * time.Hour},
+		LogLevel:               "info",
+		MetricsBindAddress:     ":9782",
+		HealthProbeBindAddress: "0",
+		DefaultImages: DefaultImages{
+			RabbitMQ: "rabbitmq:3.8.16-management",
+		},
+	}
+}
+
+// Load reads, defaults and validates the configuration file at the given path.
+func Load(path string) (*OperatorConfiguration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operator configuration file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse defaults and validates the given configuration file content.
+// Unknown fields are rejected, so that typos do not go unnoticed.
+func Parse(data []byte) (*OperatorConfiguration, error) {
+	cfg := Default()
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse operator configuration file: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate returns an error listing all invalid settings.
+func (c *OperatorConfiguration) Validate() error {
+	var errs []string
+	if c.APIVersion != APIVersion {
+		errs = append(errs, fmt.Sprintf("apiVersion must be %s", APIVersion))
+	}
+	if c.Kind != Kind {
+		errs = append(errs, fmt.Sprintf("kind must be %s", Kind))
+	}
+	if c.Controller.MaxConcurrentReconciles < 1 {
+		errs = append(errs, "controller.maxConcurrentReconciles must be at least 1")
+	}
+	rl := c.Controller.RateLimiter
+	if rl.BaseDelay.Duration <= 0 {
+		errs = append(errs, "controller.rateLimiter.baseDelay must be positive")
+	}
+	if rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+		errs = append(errs, "controller.rateLimiter.maxDelay must not be less than controller.rateLimiter.baseDelay")
+	}
+	if rl.QPS <= 0 {
+		errs = append(errs, "controller.rateLimiter.qps must be positive")
+	}
+	if rl.Burst < 1 {
+		errs = append(errs, "controller.rateLimiter.burst must be at least 1")
+	}
+	if c.Controller.NodesStatusRefreshInterval == nil || c.Controller.NodesStatusRefreshInterval.Duration < 0 {
+		errs = append(errs, "controller.nodesStatusRefreshInterval must not be negative")
+	}
+	if c.SyncPeriod == nil || c.SyncPeriod.Duration <= 0 {
+		errs = append(errs, "syncPeriod must be positive")
+	}
+	if !containsString(logLevels, c.LogLevel) {
+		errs = append(errs, fmt.Sprintf("logLevel must be one of: %s", strings.Join(logLevels, ", ")))
+	}
+	if !validBindAddress(c.MetricsBindAddress) {
+		errs = append(errs, "metricsBindAddress must be a host:port address or \"0\"")
+	}
+	if !validBindAddress(c.HealthProbeBindAddress) {
+		errs = append(errs, "healthProbeBindAddress must be a host:port address or \"0\"")
+	}
+	if c.DefaultImages.RabbitMQ == "" {
+		errs = append(errs, "defaultImages.rabbitmq must not be empty")
+	}
+	for i, rewrite := range c.ImageRegistry.Rewrites {
+		if rewrite.From == "" || rewrite.To == "" {
+			errs = append(errs, fmt.Sprintf("imageRegistry.rewrites[%d] must set from and to", i))
+		}
+	}
+	for _, secret := range c.ImageRegistry.PullSecrets {
+		if msgs := validation.IsDNS1123Subdomain(secret); len(msgs) > 0 {
+			errs = append(errs, fmt.Sprintf("imageRegistry.pullSecrets contains invalid Secret name %q: %s", secret, strings.Join(msgs, ", ")))
+		}
+	}
+	if len(c.WatchNamespaces) > 0 && c.WatchNamespaceSelector != "" {
+		errs = append(errs, "only one of watchNamespaces and watchNamespaceSelector can be set")
+	}
+	for _, ns := range c.WatchNamespaces {
+		if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+			errs = append(errs, fmt.Sprintf("watchNamespaces contains invalid namespace %q: %s", ns, strings.Join(msgs, ", ")))
+		}
+	}
+	if _, err := labels.Parse(c.WatchNamespaceSelector); err != nil {
+		errs = append(errs, fmt.Sprintf("watchNamespaceSelector is invalid: %s", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid operator configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// RestartRequired returns the settings which differ between the two configurations and only take effect after an operator restart.
+// The log level is applied without restart.
+func RestartRequired(current, updated *OperatorConfiguration) []string {
+	var changed []string
+	if !reflect.DeepEqual(current.Controller, updated.Controller) {
+		changed = append(changed, "controller")
+	}
+	if !reflect.DeepEqual(current.SyncPeriod, updated.SyncPeriod) {
+		changed = append(changed, "syncPeriod")
+	}
+	if current.MetricsBindAddress != updated.MetricsBindAddress {
+		changed = append(changed, "metricsBindAddress")
+	}
+	if current.HealthProbeBindAddress != updated.HealthProbeBindAddress {
+		changed = append(changed, "healthProbeBindAddress")
+	}
+	if !reflect.DeepEqual(current.DefaultImages, updated.DefaultImages) {
+		changed = append(changed, "defaultImages")
+	}
+	if !reflect.DeepEqual(current.ImageRegistry, updated.ImageRegistry) {
+		changed = append(changed, "imageRegistry")
+	}
+	if !reflect.DeepEqual(current.WatchNamespaces, updated.WatchNamespaces) {
+		changed = append(changed, "watchNamespaces")
+	}
+	if current.WatchNamespaceSelector != updated.WatchNamespaceSelector {
+		changed = append(changed, "watchNamespaceSelector")
+	}
+	return changed
+}
+
+func validBindAddress(address string) bool {
+	if address == "0" {
+		return true
+	}
+	_, _, err := net.SplitHostPort(address)
+	return err == nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the work queue rate limiter with the configured settings
+func (c RateLimiterConfiguration) New() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(c.BaseDelay.Duration, c.MaxDelay.Duration),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(c.QPS), c.Burst)},
+	)
+}
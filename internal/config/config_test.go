@@ -0,0 +1,152 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package config_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("OperatorConfiguration", func() {
+	Context("Parse", func() {
+		It("uses defaults for settings not set in the file", func() {
+			cfg, err := config.Parse([]byte(`
+apiVersion: config.rabbitmq.com/v1alpha1
+kind: OperatorConfiguration
+controller:
+  maxConcurrentReconciles: 10
+  rateLimiter:
+    qps: 50
+logLevel: debug
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Controller.MaxConcurrentReconciles).To(Equal(10))
+			Expect(cfg.Controller.RateLimiter.QPS).To(Equal(float64(50)))
+			Expect(cfg.Controller.RateLimiter.Burst).To(Equal(100))
+			Expect(cfg.Controller.RateLimiter.BaseDelay.Duration).To(Equal(5 * time.Millisecond))
+			Expect(cfg.LogLevel).To(Equal("debug"))
+			Expect(cfg.SyncPeriod.Duration).To(Equal(10 * time.Hour))
+			Expect(cfg.MetricsBindAddress).To(Equal(":9782"))
+			Expect(cfg.HealthProbeBindAddress).To(Equal("0"))
+			Expect(cfg.DefaultImages.RabbitMQ).To(Equal("rabbitmq:3.8.16-management"))
+		})
+
+		It("parses durations and images", func() {
+			cfg, err := config.Parse([]byte(`
+apiVersion: config.rabbitmq.com/v1alpha1
+kind: OperatorConfiguration
+syncPeriod: 1h30m
+controller:
+  rateLimiter:
+    baseDelay: 100ms
+    maxDelay: 5m
+defaultImages:
+  rabbitmq: my-registry/rabbitmq:3.9
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.SyncPeriod.Duration).To(Equal(90 * time.Minute))
+			Expect(cfg.Controller.RateLimiter.BaseDelay.Duration).To(Equal(100 * time.Millisecond))
+			Expect(cfg.Controller.RateLimiter.MaxDelay.Duration).To(Equal(5 * time.Minute))
+			Expect(cfg.DefaultImages.RabbitMQ).To(Equal("my-registry/rabbitmq:3.9"))
+		})
+
+		It("rejects unknown fields", func() {
+			_, err := config.Parse([]byte(`
+apiVersion: config.rabbitmq.com/v1alpha1
+kind: OperatorConfiguration
+controller:
+  maxConcurrentReconcile: 10
+`))
+			Expect(err).To(MatchError(ContainSubstring("maxConcurrentReconcile")))
+		})
+
+		DescribeTable("rejects invalid settings",
+			func(file, expectedError string) {
+				_, err := config.Parse([]byte(file))
+				Expect(err).To(MatchError(ContainSubstring(expectedError)))
+			},
+			Entry("unknown apiVersion", "apiVersion: v1\nkind: OperatorConfiguration", "apiVersion must be config.rabbitmq.com/v1alpha1"),
+			Entry("unknown kind", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: Config", "kind must be OperatorConfiguration"),
+			Entry("no reconcile worker", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\ncontroller:\n  maxConcurrentReconciles: -1",
+				"controller.maxConcurrentReconciles must be at least 1"),
+			Entry("max delay less than base delay", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\ncontroller:\n  rateLimiter:\n    maxDelay: 1ms",
+				"controller.rateLimiter.maxDelay must not be less than controller.rateLimiter.baseDelay"),
+			Entry("negative qps", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\ncontroller:\n  rateLimiter:\n    qps: -1",
+				"controller.rateLimiter.qps must be positive"),
+			Entry("negative nodes status refresh interval", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\ncontroller:\n  nodesStatusRefreshInterval: -1m",
+				"controller.nodesStatusRefreshInterval must not be negative"),
+			Entry("zero sync period", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nsyncPeriod: 0s", "syncPeriod must be positive"),
+			Entry("unknown log level", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nlogLevel: trace", "logLevel must be one of: debug, info, error"),
+			Entry("invalid metrics address", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nmetricsBindAddress: '9782'",
+				"metricsBindAddress must be a host:port address or \"0\""),
+			Entry("incomplete registry rewrite", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nimageRegistry:\n  rewrites:\n  - from: docker.io/",
+				"imageRegistry.rewrites[0] must set from and to"),
+			Entry("invalid pull secret", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nimageRegistry:\n  pullSecrets: [Mirror_Credentials]",
+				"imageRegistry.pullSecrets contains invalid Secret name \"Mirror_Credentials\""),
+			Entry("namespace list and selector", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nwatchNamespaces: [tenant-a]\nwatchNamespaceSelector: tenant=true",
+				"only one of watchNamespaces and watchNamespaceSelector can be set"),
+			Entry("invalid namespace", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nwatchNamespaces: [Tenant_A]",
+				"watchNamespaces contains invalid namespace \"Tenant_A\""),
+			Entry("invalid namespace selector", "apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nwatchNamespaceSelector: 'tenant in'",
+				"watchNamespaceSelector is invalid"),
+		)
+
+		It("parses the image registry settings", func() {
+			cfg, err := config.Parse([]byte(`
+apiVersion: config.rabbitmq.com/v1alpha1
+kind: OperatorConfiguration
+imageRegistry:
+  rewrites:
+  - from: docker.io/
+    to: mirror.example.com/dockerhub/
+  pullSecrets:
+  - mirror-credentials
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.ImageRegistry.Rewrites).To(ConsistOf(config.RegistryRewrite{From: "docker.io/", To: "mirror.example.com/dockerhub/"}))
+			Expect(cfg.ImageRegistry.PullSecrets).To(ConsistOf("mirror-credentials"))
+		})
+
+		It("parses the watched namespaces", func() {
+			cfg, err := config.Parse([]byte("apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nwatchNamespaces: [tenant-a, tenant-b]"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.WatchNamespaces).To(ConsistOf("tenant-a", "tenant-b"))
+			Expect(cfg.WatchNamespaceSelector).To(BeEmpty())
+		})
+
+		It("reports all invalid settings at once", func() {
+			_, err := config.Parse([]byte("apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\nlogLevel: trace\nhealthProbeBindAddress: foo"))
+			Expect(err).To(MatchError(SatisfyAll(
+				ContainSubstring("logLevel must be one of"),
+				ContainSubstring("healthProbeBindAddress must be a host:port address"),
+			)))
+		})
+	})
+
+	Context("RestartRequired", func() {
+		It("returns the changed settings which require a restart", func() {
+			current := config.Default()
+			updated := config.Default()
+			updated.LogLevel = "debug"
+			Expect(config.RestartRequired(current, updated)).To(BeEmpty())
+
+			updated.Controller.MaxConcurrentReconciles = 5
+			updated.SyncPeriod = &metav1.Duration{Duration: time.Hour}
+			updated.DefaultImages.RabbitMQ = "rabbitmq:3.9"
+			updated.WatchNamespaceSelector = "tenant=true"
+			Expect(config.RestartRequired(current, updated)).To(ConsistOf("controller", "syncPeriod", "defaultImages", "watchNamespaceSelector"))
+		})
+	})
+})
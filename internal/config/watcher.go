@@ -0,0 +1,81 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Watcher polls the configuration file for changes.
+// Files mounted from a ConfigMap are updated by the kubelet without restarting the container.
+// Changes of the log level are applied; changes of other settings are logged as requiring a restart.
+type Watcher struct {
+	Path     string
+	Interval time.Duration
+	// Configuration the operator was started with
+	Running *OperatorConfiguration
+	// SetLogLevel is called when the log level changes
+	SetLogLevel func(level string)
+	Log         logr.Logger
+
+	// last configuration read from the file
+	last *OperatorConfiguration
+	// last error reading the file, so that an invalid file is reported only once
+	lastErr string
+}
+
+// Start implements manager.Runnable
+func (w *Watcher) Start(ctx context.Context) error {
+	w.last = w.Running
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Every operator replica watches its own configuration file.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *Watcher) check() {
+	updated, err := Load(w.Path)
+	if err != nil {
+		if err.Error() != w.lastErr {
+			w.Log.Error(err, "ignoring changed operator configuration", "path", w.Path)
+			w.lastErr = err.Error()
+		}
+		return
+	}
+	w.lastErr = ""
+	if reflect.DeepEqual(w.last, updated) {
+		return
+	}
+
+	if updated.LogLevel != w.last.LogLevel {
+		w.Log.Info("changing log level", "from", w.last.LogLevel, "to", updated.LogLevel)
+		w.SetLogLevel(updated.LogLevel)
+	}
+	if changed := RestartRequired(w.Running, updated); len(changed) > 0 {
+		w.Log.Info("WARNING: operator configuration changed; restart the operator to apply the changes", "settings", changed)
+	}
+	w.last = updated
+}
@@ -0,0 +1,92 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package config_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/config"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		dir      string
+		path     string
+		cancel   context.CancelFunc
+		mu       sync.Mutex
+		logLevel string
+	)
+
+	writeConfig := func(content string) {
+		Expect(ioutil.WriteFile(path, []byte("apiVersion: config.rabbitmq.com/v1alpha1\nkind: OperatorConfiguration\n"+content), 0644)).To(Succeed())
+	}
+
+	currentLogLevel := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return logLevel
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "operator-config")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "config.yaml")
+		writeConfig("logLevel: info")
+		running, err := config.Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		logLevel = running.LogLevel
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		watcher := &config.Watcher{
+			Path:     path,
+			Interval: 10 * time.Millisecond,
+			Running:  running,
+			SetLogLevel: func(level string) {
+				mu.Lock()
+				defer mu.Unlock()
+				logLevel = level
+			},
+			Log: zap.New(zap.WriteTo(GinkgoWriter)),
+		}
+		Expect(watcher.NeedLeaderElection()).To(BeFalse())
+		go func() {
+			defer GinkgoRecover()
+			Expect(watcher.Start(ctx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		cancel()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("applies log level changes", func() {
+		writeConfig("logLevel: debug")
+		Eventually(currentLogLevel).Should(Equal("debug"))
+	})
+
+	It("ignores invalid configuration files", func() {
+		writeConfig("logLevel: trace")
+		Consistently(currentLogLevel, 0.1).Should(Equal("info"))
+
+		writeConfig("logLevel: error")
+		Eventually(currentLogLevel).Should(Equal("error"))
+	})
+})
@@ -0,0 +1,153 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+// Package cron parses the five field cron expressions of maintenance windows and computes their activation times in UTC.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression. Each field is a bit set of the values it matches.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// as in standard cron, a day matches either field if both day of month and day of week are restricted
+	dayOfMonthStar, dayOfWeekStar bool
+}
+
+// Parse parses a cron expression with the fields minute, hour, day of month, month and day of week.
+// Each field is *, a value, a range a-b, optionally followed by a step /n, or a comma separated list of these.
+// Sunday is 0 or 7.
+func Parse(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields: minute, hour, day of month, month and day of week", expression)
+	}
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		bits, err := parseField(parts[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+		}
+		values[i] = bits
+	}
+	schedule := &Schedule{
+		minute:         values[0],
+		hour:           values[1],
+		dayOfMonth:     values[2],
+		month:          values[3],
+		dayOfWeek:      values[4],
+		dayOfMonthStar: strings.HasPrefix(parts[2], "*"),
+		dayOfWeekStar:  strings.HasPrefix(parts[4], "*"),
+	}
+	// Sunday can be written as 7
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek |= 1
+	}
+	return schedule, nil
+}
+
+func parseField(expression string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expression, ",") {
+		rangeExpression, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rangeExpression = item[:i]
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", item[i+1:], f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeExpression != "*" {
+			bounds := strings.SplitN(rangeExpression, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseValue(bounds[1], f); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				// a/n is shorthand for a-max/n
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangeExpression, f.name)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, must be between %d and %d", value, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first activation of the schedule after t, in UTC.
+// It returns the zero time if the schedule does not activate within the next five years, e.g. for February 30.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
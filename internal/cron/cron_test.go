@@ -0,0 +1,78 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/rabbitmq/cluster-operator/internal/cron"
+)
+
+var _ = Describe("Cron", func() {
+	// Wednesday
+	now := time.Date(2021, time.March, 17, 10, 30, 0, 0, time.UTC)
+
+	table.DescribeTable("Next",
+		func(expression string, expected time.Time) {
+			schedule, err := cron.Parse(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.Next(now)).To(Equal(expected))
+		},
+		table.Entry("every minute", "* * * * *", time.Date(2021, time.March, 17, 10, 31, 0, 0, time.UTC)),
+		table.Entry("later the same day", "0 22 * * *", time.Date(2021, time.March, 17, 22, 0, 0, 0, time.UTC)),
+		table.Entry("the next day", "0 2 * * *", time.Date(2021, time.March, 18, 2, 0, 0, 0, time.UTC)),
+		table.Entry("step", "*/20 * * * *", time.Date(2021, time.March, 17, 10, 40, 0, 0, time.UTC)),
+		table.Entry("list", "15,45 * * * *", time.Date(2021, time.March, 17, 10, 45, 0, 0, time.UTC)),
+		table.Entry("range of hours", "0 1-3 * * *", time.Date(2021, time.March, 18, 1, 0, 0, 0, time.UTC)),
+		table.Entry("Sunday as 0", "0 3 * * 0", time.Date(2021, time.March, 21, 3, 0, 0, 0, time.UTC)),
+		table.Entry("Sunday as 7", "0 3 * * 7", time.Date(2021, time.March, 21, 3, 0, 0, 0, time.UTC)),
+		table.Entry("weekdays", "0 3 * * 1-5", time.Date(2021, time.March, 18, 3, 0, 0, 0, time.UTC)),
+		table.Entry("day of month", "0 0 1 * *", time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)),
+		table.Entry("month", "0 0 1 1 *", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		table.Entry("either day field if both are restricted", "0 0 20 * 5", time.Date(2021, time.March, 19, 0, 0, 0, 0, time.UTC)),
+		table.Entry("leap day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("is strictly after the given time", func() {
+		schedule, err := cron.Parse("30 10 * * *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Next(now)).To(Equal(time.Date(2021, time.March, 18, 10, 30, 0, 0, time.UTC)))
+	})
+
+	It("evaluates the schedule in UTC", func() {
+		schedule, err := cron.Parse("0 12 * * *")
+		Expect(err).NotTo(HaveOccurred())
+		local := now.In(time.FixedZone("UTC+2", 2*60*60))
+		Expect(schedule.Next(local)).To(Equal(time.Date(2021, time.March, 17, 12, 0, 0, 0, time.UTC)))
+	})
+
+	It("returns the zero time if the schedule never activates", func() {
+		schedule, err := cron.Parse("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Next(now).IsZero()).To(BeTrue())
+	})
+
+	table.DescribeTable("invalid expressions",
+		func(expression, message string) {
+			_, err := cron.Parse(expression)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		table.Entry("too few fields", "0 2 * *", "must have 5 fields"),
+		table.Entry("value out of range", "60 2 * * *", `invalid value "60" in minute field`),
+		table.Entry("not a number", "0 two * * *", `invalid value "two" in hour field`),
+		table.Entry("inverted range", "0 5-3 * * *", `invalid range "5-3" in hour field`),
+		table.Entry("zero step", "*/0 * * * *", `invalid step "0" in minute field`),
+		table.Entry("day of month zero", "0 0 0 * *", "day of month field"),
+		table.Entry("day of week eight", "0 0 * * 8", "day of week field"),
+	)
+})
@@ -0,0 +1,100 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	communityPluginsDir = "/operator/community-plugins"
+	// default plugins directory of the RabbitMQ image
+	defaultPluginsDir = "/opt/rabbitmq/plugins"
+)
+
+func validateCommunityPlugins(plugins []rabbitmqv1beta1.CommunityPlugin) error {
+	for _, p := range plugins {
+		if (p.URL == "") == (p.Image == "") {
+			return fmt.Errorf("community plugin %s must set exactly one of url or image", p.Name)
+		}
+	}
+	return nil
+}
+
+func communityPluginArchive(plugin rabbitmqv1beta1.CommunityPlugin) string {
+	return fmt.Sprintf("%s/%s.ez", communityPluginsDir, plugin.Name)
+}
+
+func communityPluginPathInImage(plugin rabbitmqv1beta1.CommunityPlugin) string {
+	if plugin.Path != "" {
+		return plugin.Path
+	}
+	return fmt.Sprintf("/plugins/%s.ez", plugin.Name)
+}
+
+// communityPluginsSetupCommand returns the part of the setup container command which
+// downloads all community plugins with a URL and verifies the checksums of all community plugins.
+// Plugins from images are copied by the init containers running before the setup container.
+// The RabbitMQ image does not necessarily ship with curl or wget, therefore the download is done with Erlang's httpc.
+// If chown is true, the owner of the plugin archives is changed to the RabbitMQ user.
+func communityPluginsSetupCommand(plugins []rabbitmqv1beta1.CommunityPlugin, chown bool) string {
+	if len(plugins) == 0 {
+		return ""
+	}
+	cmds := []string{fmt.Sprintf("mkdir -p %s", communityPluginsDir)}
+	for _, p := range plugins {
+		if p.URL == "" {
+			continue
+		}
+		cmds = append(cmds, "erl -noinput -eval 'try "+
+			"{ok, _} = application:ensure_all_started(inets), "+
+			"{ok, _} = application:ensure_all_started(ssl), "+
+			fmt.Sprintf("{ok, saved_to_file} = httpc:request(get, {\"%s\", []}, [{timeout, 300000}], [{stream, \"%s\"}]), ", p.URL, communityPluginArchive(p))+
+			"halt(0) "+
+			"catch C:R -> io:format(\"failed to download community plugin: ~p:~p~n\", [C, R]), halt(1) end.'")
+	}
+	for _, p := range plugins {
+		cmds = append(cmds, fmt.Sprintf("echo '%s  %s' | sha256sum -c -", p.SHA256, communityPluginArchive(p)))
+	}
+	if chown {
+		cmds = append(cmds, fmt.Sprintf("chown -R 999:999 %s", communityPluginsDir))
+	}
+	return " ; " + strings.Join(cmds, " && ")
+}
+
+// communityPluginInitContainers returns an init container for every community plugin shipped in an image.
+// Each init container copies the plugin archive from its image into the rabbitmq-plugins volume.
+func communityPluginInitContainers(plugins []rabbitmqv1beta1.CommunityPlugin, resources corev1.ResourceRequirements) []corev1.Container {
+	var containers []corev1.Container
+	for i, p := range plugins {
+		if p.Image == "" {
+			continue
+		}
+		containers = append(containers, corev1.Container{
+			Name:  fmt.Sprintf("copy-community-plugin-%d", i),
+			Image: p.Image,
+			Command: []string{
+				"sh", "-c", fmt.Sprintf("mkdir -p %s && cp %s %s", communityPluginsDir, communityPluginPathInImage(p), communityPluginArchive(p)),
+			},
+			Resources: resources,
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "rabbitmq-plugins",
+					MountPath: "/operator",
+				},
+			},
+		})
+	}
+	return containers
+}
@@ -19,6 +19,7 @@ import (
 
 	"gopkg.in/ini.v1"
 
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/internal/metadata"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -80,6 +81,10 @@ func (builder *ServerConfigMapBuilder) UpdateMayRequireStsRecreate() bool {
 func (builder *ServerConfigMapBuilder) Update(object client.Object) error {
 	configMap := object.(*corev1.ConfigMap)
 
+	if err := validateConfig(builder.Instance.Spec.Rabbitmq.Config); err != nil {
+		return err
+	}
+
 	ini.PrettySection = false // Remove trailing new line because rabbitmq.conf has only a default section.
 	operatorConfiguration, err := ini.Load([]byte(defaultRabbitmqConf))
 	if err != nil {
@@ -91,6 +96,19 @@ func (builder *ServerConfigMapBuilder) Update(object client.Object) error {
 		return err
 	}
 
+	if pathPrefix := builder.Instance.ManagementPathPrefix(); pathPrefix != "" {
+		if _, err := defaultSection.NewKey("management.path_prefix", pathPrefix); err != nil {
+			return err
+		}
+	}
+
+	if persistenceVolumeEnabled(builder.Instance.Spec.Persistence.Volumes, rabbitmqv1beta1.PersistenceVolumeLogs) {
+		// log to the console in addition to the log file on the logs volume
+		if _, err := defaultSection.NewKey("log.console", "true"); err != nil {
+			return err
+		}
+	}
+
 	userConfiguration := ini.Empty(ini.LoadOptions{})
 	userConfigurationSection := userConfiguration.Section("")
 
@@ -222,6 +240,12 @@ func (builder *ServerConfigMapBuilder) Update(object client.Object) error {
 	rmqConfBuffer.Reset()
 
 	rmqProperties := builder.Instance.Spec.Rabbitmq
+	for _, key := range sortedConfigKeys(rmqProperties.Config) {
+		if _, err := userConfigurationSection.NewKey(key, rmqProperties.Config[key]); err != nil {
+			return err
+		}
+	}
+
 	if err := userConfiguration.Append([]byte(rmqProperties.AdditionalConfig)); err != nil {
 		return fmt.Errorf("failed to append spec.rabbitmq.additionalConfig: %w", err)
 	}
@@ -233,7 +257,7 @@ func (builder *ServerConfigMapBuilder) Update(object client.Object) error {
 	configMap.Data["userDefinedConfiguration.conf"] = rmqConfBuffer.String()
 
 	updateProperty(configMap.Data, "advanced.config", rmqProperties.AdvancedConfig)
-	updateProperty(configMap.Data, "rabbitmq-env.conf", rmqProperties.EnvConfig)
+	updateProperty(configMap.Data, "rabbitmq-env.conf", envConfig(builder.Instance))
 
 	if err := controllerutil.SetControllerReference(builder.Instance, configMap, builder.Scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %v", err)
@@ -145,6 +145,34 @@ var _ = Describe("GenerateServerConfigMap", func() {
 			Expect(configMap.Data).To(HaveKeyWithValue("operatorDefaults.conf", expectedConfiguration))
 		})
 
+		When("a logs volume is configured", func() {
+			It("keeps logging to the console", func() {
+				instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+					{Role: rabbitmqv1beta1.PersistenceVolumeLogs},
+				}
+
+				expectedConfiguration := iniString(defaultRabbitmqConf(builder.Instance.Name) + `
+log.console                              = true`)
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("operatorDefaults.conf", expectedConfiguration))
+			})
+		})
+
+		When("the management UI is exposed under a path prefix", func() {
+			It("sets management.path_prefix", func() {
+				instance.Spec.Management = &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+					Ingress: &rabbitmqv1beta1.ManagementIngressSpec{Host: "rabbitmq.example.com", PathPrefix: "/rabbitmq/"},
+				}
+
+				expectedConfiguration := iniString(defaultRabbitmqConf(builder.Instance.Name) + `
+management.path_prefix                   = /rabbitmq`)
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("operatorDefaults.conf", expectedConfiguration))
+			})
+		})
+
 		When("valid userDefinedConfiguration is provided", func() {
 			It("adds configurations in a new rabbitmq configuration", func() {
 				userDefinedConfiguration := "cluster_formation.peer_discovery_backend = my-backend\n" +
@@ -168,6 +196,54 @@ var _ = Describe("GenerateServerConfigMap", func() {
 			})
 		})
 
+		When("structured configuration is provided", func() {
+			It("adds the settings in order of their keys before additionalConfig", func() {
+				instance.Spec.Rabbitmq.Config = map[string]string{
+					"vm_memory_high_watermark.relative": "0.6",
+					"log.console.level":                 "debug",
+					"my.custom.setting":                 "value",
+				}
+				instance.Spec.Rabbitmq.AdditionalConfig = "heartbeat = 30"
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("userDefinedConfiguration.conf", iniString(`log.console.level                 = debug
+my.custom.setting                 = value
+vm_memory_high_watermark.relative = 0.6
+heartbeat                         = 30`)))
+			})
+
+			It("rejects settings managed by the operator", func() {
+				instance.Spec.Rabbitmq.Config = map[string]string{
+					"cluster_formation.k8s.host":            "example.com",
+					"listeners.tcp.default":                 "5673",
+					"mqtt.listeners.tcp.default":            "1884",
+					"management.tcp.port":                   "15673",
+					"total_memory_available_override_value": "1000",
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(MatchError("invalid spec.rabbitmq.config: " +
+					"cluster_formation.k8s.host is managed by the operator; " +
+					"listeners.tcp.default is managed by the operator; " +
+					"management.tcp.port is managed by the operator; " +
+					"mqtt.listeners.tcp.default is managed by the operator; " +
+					"total_memory_available_override_value is managed by the operator"))
+			})
+
+			It("rejects values not matching the type of known settings", func() {
+				instance.Spec.Rabbitmq.Config = map[string]string{
+					"heartbeat":                         "30s",
+					"disk_free_limit.absolute":          "2 gigabytes",
+					"log.file.level":                    "verbose",
+					"vm_memory_high_watermark.relative": "0.6",
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(MatchError("invalid spec.rabbitmq.config: " +
+					`disk_free_limit.absolute must be an amount of bytes, e.g. 2GB, got "2 gigabytes"; ` +
+					`heartbeat must be an integer, got "30s"; ` +
+					`log.file.level must be one of debug, info, warning, error, critical, none, got "verbose"`))
+			})
+		})
+
 		Context("advanced.config", func() {
 			It("sets data.advancedConfig when provided", func() {
 				instance.Spec.Rabbitmq.AdvancedConfig = "[my-awesome-config]."
@@ -214,6 +290,27 @@ CONSOLE_LOG=new`
 				Expect(configMap.Data).ToNot(HaveKey("rabbitmq-env.conf"))
 			})
 
+			It("appends the Ra write-ahead log directory to the additional Erlang arguments when a quorum-wal volume is configured", func() {
+				instance.Spec.Rabbitmq.EnvConfig = `SERVER_ADDITIONAL_ERL_ARGS="-kernel inet_dist_listen_min 25672"
+`
+				instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+					{Role: rabbitmqv1beta1.PersistenceVolumeQuorumWAL},
+				}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq-env.conf", `SERVER_ADDITIONAL_ERL_ARGS="-kernel inet_dist_listen_min 25672"
+RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS="${RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS:-$SERVER_ADDITIONAL_ERL_ARGS} -ra wal_data_dir \"/var/lib/rabbitmq/quorum-wal/\""`))
+			})
+
+			It("passes the zone of the node to RabbitMQ when spec.topology is set", func() {
+				instance.Spec.Rabbitmq.EnvConfig = ""
+				instance.Spec.Topology = &rabbitmqv1beta1.RabbitmqClusterTopologySpec{}
+
+				Expect(configMapBuilder.Update(configMap)).To(Succeed())
+				Expect(configMap.Data).To(HaveKeyWithValue("rabbitmq-env.conf",
+					`RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS="${RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS:-$SERVER_ADDITIONAL_ERL_ARGS} -rabbit zone \"$(cat /etc/pod-info/zone)\""`))
+			})
+
 			Context("rabbitmq-env.conf is set", func() {
 				When("new envConf is empty", func() {
 					It("removes rabbitmq-env.conf key from configMap", func() {
@@ -12,6 +12,9 @@ package resource
 import (
 	"bytes"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -27,8 +30,21 @@ const (
 	DefaultUserSecretName = "default-user"
 	bindingProvider       = "rabbitmq"
 	bindingType           = "rabbitmq"
+	bindingVhost          = "/"
 )
 
+// bindingProtocols are the protocol specific URIs of the binding Secret, each served by a plain and a TLS listener
+var bindingProtocols = []struct {
+	key, listener, tlsListener string
+}{
+	{key: "management-uri", listener: "management", tlsListener: "management-tls"},
+	{key: "stream-uri", listener: "stream", tlsListener: "streams"},
+	{key: "mqtt-uri", listener: "mqtt", tlsListener: "mqtts"},
+	{key: "stomp-uri", listener: "stomp", tlsListener: "stomps"},
+	{key: "web-mqtt-uri", listener: "web-mqtt", tlsListener: "web-mqtt-tls"},
+	{key: "web-stomp-uri", listener: "web-stomp", tlsListener: "web-stomp-tls"},
+}
+
 type DefaultUserSecretBuilder struct {
 	*RabbitmqResourceBuilder
 }
@@ -79,6 +95,7 @@ func (builder *DefaultUserSecretBuilder) Update(object client.Object) error {
 	secret := object.(*corev1.Secret)
 	secret.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
 	secret.Annotations = metadata.ReconcileAndFilterAnnotations(secret.GetAnnotations(), builder.Instance.Annotations)
+	builder.updateConnectionDetails(secret)
 
 	if err := controllerutil.SetControllerReference(builder.Instance, secret, builder.Scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %v", err)
@@ -87,6 +104,54 @@ func (builder *DefaultUserSecretBuilder) Update(object client.Object) error {
 	return nil
 }
 
+// updateConnectionDetails sets the keys of the binding Secret describing how to connect to the client Service.
+// They are derived on every update, so that they follow changes of the TLS settings and enabled plugins.
+// The ca.crt key is maintained by the controller, since it is copied from the TLS Secrets.
+func (builder *DefaultUserSecretBuilder) updateConnectionDetails(secret *corev1.Secret) {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	host := ServiceHost(builder.Instance)
+	ports := builder.Service().generateServicePortsMap()
+	tls := builder.Instance.TLSEnabled()
+
+	secret.Data["host"] = []byte(host)
+	secret.Data["vhost"] = []byte(bindingVhost)
+	secret.Data["tls"] = []byte(strconv.FormatBool(tls))
+	if amqp, ok := bindingPort(ports, "amqp", "amqps", tls); ok {
+		scheme := "amqp"
+		if amqp.Name == "amqps" {
+			scheme = "amqps"
+		}
+		uri := url.URL{
+			Scheme: scheme,
+			User:   url.UserPassword(string(secret.Data["username"]), string(secret.Data["password"])),
+			Host:   net.JoinHostPort(host, strconv.Itoa(int(amqp.Port))),
+		}
+		secret.Data["port"] = []byte(strconv.Itoa(int(amqp.Port)))
+		secret.Data["uri"] = []byte(fmt.Sprintf("%s/%s", uri.String(), url.PathEscape(bindingVhost)))
+	}
+
+	for _, protocol := range bindingProtocols {
+		port, ok := bindingPort(ports, protocol.listener, protocol.tlsListener, tls)
+		if !ok {
+			delete(secret.Data, protocol.key)
+			continue
+		}
+		uri, _ := ListenerURL(builder.Instance, port.Name, host, port.Port)
+		secret.Data[protocol.key] = []byte(uri)
+	}
+}
+
+// bindingPort prefers the TLS listener if TLS is enabled
+func bindingPort(ports map[string]corev1.ServicePort, listener, tlsListener string, tls bool) (corev1.ServicePort, bool) {
+	if port, ok := ports[tlsListener]; ok && tls {
+		return port, true
+	}
+	port, ok := ports[listener]
+	return port, ok
+}
+
 func generateDefaultUserConf(username, password string) ([]byte, error) {
 	ini.PrettySection = false // Remove trailing new line because default_user.conf has only a default section.
 	cfg, err := ini.Load([]byte{})
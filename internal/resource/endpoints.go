@@ -0,0 +1,62 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+)
+
+type listenerURLFormat struct {
+	scheme string
+	path   string
+}
+
+// URL schemes and paths of the listeners, keyed by the port names of the client Service
+var listenerURLFormats = map[string]listenerURLFormat{
+	"amqp":           {scheme: "amqp"},
+	"amqps":          {scheme: "amqps"},
+	"management":     {scheme: "http", path: "/"},
+	"management-tls": {scheme: "https", path: "/"},
+	"prometheus":     {scheme: "http", path: "/metrics"},
+	"prometheus-tls": {scheme: "https", path: "/metrics"},
+	"stream":         {scheme: "rabbitmq-stream"},
+	"streams":        {scheme: "rabbitmq-stream+tls"},
+	"mqtt":           {scheme: "mqtt"},
+	"mqtts":          {scheme: "mqtts"},
+	"stomp":          {scheme: "stomp"},
+	"stomps":         {scheme: "stomp+ssl"},
+	"web-mqtt":       {scheme: "ws", path: "/ws"},
+	"web-mqtt-tls":   {scheme: "wss", path: "/ws"},
+	"web-stomp":      {scheme: "ws", path: "/ws"},
+	"web-stomp-tls":  {scheme: "wss", path: "/ws"},
+}
+
+// ListenerURL returns the URL of the listener with the given client Service port name at the given host and port.
+// It returns false for ports which are not RabbitMQ listeners, e.g. additional ports added through the Service override.
+func ListenerURL(instance *rabbitmqv1beta1.RabbitmqCluster, portName, host string, port int32) (string, bool) {
+	format, ok := listenerURLFormats[portName]
+	if !ok {
+		return "", false
+	}
+	path := format.path
+	if portName == "management" || portName == "management-tls" {
+		path = instance.ManagementPathPrefix() + path
+	}
+	return fmt.Sprintf("%s://%s%s", format.scheme, net.JoinHostPort(host, strconv.Itoa(int(port))), path), true
+}
+
+// ServiceHost returns the cluster-internal DNS name of the client Service
+func ServiceHost(instance *rabbitmqv1beta1.RabbitmqCluster) string {
+	return fmt.Sprintf("%s.%s.svc", instance.ChildResourceName(ServiceSuffix), instance.Namespace)
+}
@@ -0,0 +1,78 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Endpoints", func() {
+	var instance rabbitmqv1beta1.RabbitmqCluster
+
+	BeforeEach(func() {
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "foo-namespace",
+			},
+		}
+	})
+
+	DescribeTable("ListenerURL",
+		func(portName string, port int32, expected string) {
+			url, ok := resource.ListenerURL(&instance, portName, "foo.foo-namespace.svc", port)
+			Expect(ok).To(BeTrue())
+			Expect(url).To(Equal(expected))
+		},
+		Entry("amqp", "amqp", int32(5672), "amqp://foo.foo-namespace.svc:5672"),
+		Entry("amqps", "amqps", int32(5671), "amqps://foo.foo-namespace.svc:5671"),
+		Entry("management", "management", int32(15672), "http://foo.foo-namespace.svc:15672/"),
+		Entry("management-tls", "management-tls", int32(15671), "https://foo.foo-namespace.svc:15671/"),
+		Entry("prometheus", "prometheus", int32(15692), "http://foo.foo-namespace.svc:15692/metrics"),
+		Entry("prometheus-tls", "prometheus-tls", int32(15691), "https://foo.foo-namespace.svc:15691/metrics"),
+		Entry("stream", "stream", int32(5552), "rabbitmq-stream://foo.foo-namespace.svc:5552"),
+		Entry("streams", "streams", int32(5551), "rabbitmq-stream+tls://foo.foo-namespace.svc:5551"),
+		Entry("mqtt", "mqtt", int32(1883), "mqtt://foo.foo-namespace.svc:1883"),
+		Entry("mqtts", "mqtts", int32(8883), "mqtts://foo.foo-namespace.svc:8883"),
+		Entry("stomp", "stomp", int32(61613), "stomp://foo.foo-namespace.svc:61613"),
+		Entry("stomps", "stomps", int32(61614), "stomp+ssl://foo.foo-namespace.svc:61614"),
+		Entry("web-mqtt", "web-mqtt", int32(15675), "ws://foo.foo-namespace.svc:15675/ws"),
+		Entry("web-mqtt-tls", "web-mqtt-tls", int32(15676), "wss://foo.foo-namespace.svc:15676/ws"),
+		Entry("web-stomp", "web-stomp", int32(15674), "ws://foo.foo-namespace.svc:15674/ws"),
+		Entry("web-stomp-tls", "web-stomp-tls", int32(15673), "wss://foo.foo-namespace.svc:15673/ws"),
+	)
+
+	It("does not return a URL for ports which are not RabbitMQ listeners", func() {
+		_, ok := resource.ListenerURL(&instance, "my-sidecar", "foo.foo-namespace.svc", 8080)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("adds the path prefix of the management UI", func() {
+		instance.Spec.Management = &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+			Ingress: &rabbitmqv1beta1.ManagementIngressSpec{Host: "rabbitmq.example.com", PathPrefix: "/rabbitmq/"},
+		}
+		url, _ := resource.ListenerURL(&instance, "management", "foo.foo-namespace.svc", 15672)
+		Expect(url).To(Equal("http://foo.foo-namespace.svc:15672/rabbitmq/"))
+	})
+
+	It("encloses IPv6 addresses in brackets", func() {
+		url, _ := resource.ListenerURL(&instance, "amqp", "2001:db8::1", 5672)
+		Expect(url).To(Equal("amqp://[2001:db8::1]:5672"))
+	})
+
+	It("returns the cluster-internal DNS name of the client Service", func() {
+		Expect(resource.ServiceHost(&instance)).To(Equal("foo.foo-namespace.svc"))
+	})
+})
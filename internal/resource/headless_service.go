@@ -22,7 +22,7 @@ import (
 )
 
 const (
-	headlessServiceSuffix = "nodes"
+	HeadlessServiceSuffix = "nodes"
 )
 
 type HeadlessServiceBuilder struct {
@@ -40,7 +40,7 @@ func (builder *HeadlessServiceBuilder) UpdateMayRequireStsRecreate() bool {
 func (builder *HeadlessServiceBuilder) Build() (client.Object, error) {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      builder.Instance.ChildResourceName(headlessServiceSuffix),
+			Name:      builder.Instance.ChildResourceName(HeadlessServiceSuffix),
 			Namespace: builder.Instance.Namespace,
 		},
 	}, nil
@@ -0,0 +1,83 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const dockerHubRegistry = "docker.io/"
+
+// ImageRegistryRewrite replaces the prefix From of image names with To,
+// e.g. "docker.io/" with "mirror.example.com/dockerhub/".
+type ImageRegistryRewrite struct {
+	From string
+	To   string
+}
+
+// RewriteImage replaces the longest matching prefix of the image name.
+// Docker Hub images without registry, such as "rabbitmq:3.8.16", also match prefixes of
+// their fully qualified name "docker.io/library/rabbitmq:3.8.16".
+func RewriteImage(image string, rewrites []ImageRegistryRewrite) string {
+	qualified := qualifiedImageName(image)
+	var match *ImageRegistryRewrite
+	var matched string
+	for i, rewrite := range rewrites {
+		for _, name := range []string{image, qualified} {
+			if strings.HasPrefix(name, rewrite.From) && (match == nil || len(rewrite.From) > len(match.From)) {
+				match = &rewrites[i]
+				matched = name
+			}
+		}
+	}
+	if match == nil {
+		return image
+	}
+	return match.To + strings.TrimPrefix(matched, match.From)
+}
+
+// qualifiedImageName returns the image name including the registry and, for Docker Hub, the library namespace.
+func qualifiedImageName(image string) string {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return image
+	}
+	if len(parts) == 1 {
+		return dockerHubRegistry + "library/" + image
+	}
+	return dockerHubRegistry + image
+}
+
+// applyImageRegistrySettings rewrites the images of all containers and adds the default image pull secrets
+// which are not yet set in the Pod spec.
+func (builder *StatefulSetBuilder) applyImageRegistrySettings(podSpec *corev1.PodSpec) {
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Image = RewriteImage(podSpec.InitContainers[i].Image, builder.ImageRegistryRewrites)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Image = RewriteImage(podSpec.Containers[i].Image, builder.ImageRegistryRewrites)
+	}
+	for _, secret := range builder.DefaultImagePullSecrets {
+		if !containsLocalObjectReference(podSpec.ImagePullSecrets, secret) {
+			podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, secret)
+		}
+	}
+}
+
+func containsLocalObjectReference(refs []corev1.LocalObjectReference, ref corev1.LocalObjectReference) bool {
+	for _, r := range refs {
+		if r.Name == ref.Name {
+			return true
+		}
+	}
+	return false
+}
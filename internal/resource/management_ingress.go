@@ -0,0 +1,224 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// The Gateway API types are not imported for the same reason as the Prometheus Operator types.
+var HTTPRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+const (
+	managementIngressName = "management"
+	managementPort        = 15672
+	managementTLSPort     = 15671
+)
+
+type ManagementIngressBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) ManagementIngress() *ManagementIngressBuilder {
+	return &ManagementIngressBuilder{builder}
+}
+
+func (builder *ManagementIngressBuilder) Build() (client.Object, error) {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(managementIngressName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *ManagementIngressBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+// Needed returns true if the management UI is exposed and the Gateway API is not installed
+func (builder *ManagementIngressBuilder) Needed() bool {
+	return builder.Instance.ManagementIngress() != nil && !builder.GatewayAPIInstalled
+}
+
+func (builder *ManagementIngressBuilder) Update(object client.Object) error {
+	ingress := object.(*networkingv1.Ingress)
+	spec := builder.Instance.ManagementIngress()
+
+	ingress.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	ingress.Annotations = metadata.ReconcileAnnotations(
+		metadata.ReconcileAndFilterAnnotations(ingress.Annotations, builder.Instance.Annotations),
+		builder.backendAnnotations(),
+		spec.Annotations,
+	)
+
+	pathType := networkingv1.PathTypePrefix
+	path := builder.Instance.ManagementPathPrefix()
+	if path == "" {
+		path = "/"
+	}
+	ingress.Spec = networkingv1.IngressSpec{
+		IngressClassName: spec.IngressClassName,
+		Rules: []networkingv1.IngressRule{{
+			Host: spec.Host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     path,
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: builder.Instance.ChildResourceName(ServiceSuffix),
+								Port: networkingv1.ServiceBackendPort{Number: managementBackendPort(builder.Instance)},
+							},
+						},
+					}},
+				},
+			},
+		}},
+	}
+	if spec.TLS != nil && spec.TLS.SecretName != "" && !spec.TLS.Passthrough {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{spec.Host},
+			SecretName: spec.TLS.SecretName,
+		}}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, ingress, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+// backendAnnotations configures ingress-nginx for backends only serving HTTPS; other ingress controllers need to be configured through spec.management.ingress.annotations
+func (builder *ManagementIngressBuilder) backendAnnotations() map[string]string {
+	if managementBackendPort(builder.Instance) != managementTLSPort {
+		return nil
+	}
+	annotations := map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+	}
+	if tls := builder.Instance.ManagementIngress().TLS; tls != nil && tls.Passthrough {
+		annotations["nginx.ingress.kubernetes.io/ssl-passthrough"] = "true"
+	}
+	return annotations
+}
+
+type ManagementHTTPRouteBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) ManagementHTTPRoute() *ManagementHTTPRouteBuilder {
+	return &ManagementHTTPRouteBuilder{builder}
+}
+
+func (builder *ManagementHTTPRouteBuilder) Build() (client.Object, error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	route.SetName(builder.Instance.ChildResourceName(managementIngressName))
+	route.SetNamespace(builder.Instance.Namespace)
+	return route, nil
+}
+
+func (builder *ManagementHTTPRouteBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+// Needed returns true if the management UI is exposed and the Gateway API is installed
+func (builder *ManagementHTTPRouteBuilder) Needed() bool {
+	return builder.Instance.ManagementIngress() != nil && builder.GatewayAPIInstalled
+}
+
+// Update routes the host and path prefix to the client Service. TLS is terminated by the Gateway.
+func (builder *ManagementHTTPRouteBuilder) Update(object client.Object) error {
+	route := object.(*unstructured.Unstructured)
+	spec := builder.Instance.ManagementIngress()
+	// an HTTPRoute without parentRefs is not attached to any Gateway and does not expose the management UI
+	if spec.Gateway == nil {
+		return fmt.Errorf("spec.management.ingress.gateway is required because the Gateway API is installed")
+	}
+
+	path := builder.Instance.ManagementPathPrefix()
+	if path == "" {
+		path = "/"
+	}
+	routeSpec := map[string]interface{}{
+		"hostnames": []interface{}{spec.Host},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": path,
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": builder.Instance.ChildResourceName(ServiceSuffix),
+						"port": int64(managementBackendPort(builder.Instance)),
+					},
+				},
+			},
+		},
+	}
+	parentRef := map[string]interface{}{
+		"name": spec.Gateway.Name,
+	}
+	if spec.Gateway.Namespace != "" {
+		parentRef["namespace"] = spec.Gateway.Namespace
+	}
+	if spec.Gateway.SectionName != "" {
+		parentRef["sectionName"] = spec.Gateway.SectionName
+	}
+	routeSpec["parentRefs"] = []interface{}{parentRef}
+	route.Object["spec"] = routeSpec
+
+	route.SetLabels(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels))
+	route.SetAnnotations(metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(route.GetAnnotations(), builder.Instance.Annotations), spec.Annotations))
+
+	if err := controllerutil.SetControllerReference(builder.Instance, route, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+// managementBackendPort returns the management-tls port if TLS is passed through to the RabbitMQ nodes
+// or if the RabbitMQ nodes do not serve the management UI via plain HTTP
+func managementBackendPort(instance *rabbitmqv1beta1.RabbitmqCluster) int32 {
+	tls := instance.ManagementIngress().TLS
+	if instance.DisableNonTLSListeners() || (tls != nil && tls.Passthrough && instance.TLSEnabled()) {
+		return managementTLSPort
+	}
+	return managementPort
+}
+
+// ManagementURL returns the URL of the management UI, or an empty string if the management UI is not exposed
+func ManagementURL(instance *rabbitmqv1beta1.RabbitmqCluster) string {
+	spec := instance.ManagementIngress()
+	if spec == nil {
+		return ""
+	}
+	scheme := "http"
+	if spec.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s/", scheme, spec.Host, instance.ManagementPathPrefix())
+}
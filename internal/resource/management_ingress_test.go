@@ -0,0 +1,177 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Management UI exposure", func() {
+	var (
+		instance rabbitmqv1beta1.RabbitmqCluster
+		builder  *resource.RabbitmqResourceBuilder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Management: &rabbitmqv1beta1.RabbitmqClusterManagementSpec{
+					Ingress: &rabbitmqv1beta1.ManagementIngressSpec{
+						Host:       "rabbitmq.example.com",
+						PathPrefix: "/rabbitmq/",
+					},
+				},
+			},
+		}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+	})
+
+	Context("Ingress", func() {
+		var (
+			ingressBuilder *resource.ManagementIngressBuilder
+			ingress        *networkingv1.Ingress
+		)
+
+		BeforeEach(func() {
+			ingressBuilder = builder.ManagementIngress()
+			obj, err := ingressBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			ingress = obj.(*networkingv1.Ingress)
+		})
+
+		It("is only needed if the management UI is exposed and the Gateway API is not installed", func() {
+			Expect(ingress.Name).To(Equal("a-name-management"))
+			Expect(ingressBuilder.Needed()).To(BeTrue())
+			builder.GatewayAPIInstalled = true
+			Expect(ingressBuilder.Needed()).To(BeFalse())
+			builder.GatewayAPIInstalled = false
+			instance.Spec.Management = nil
+			Expect(ingressBuilder.Needed()).To(BeFalse())
+		})
+
+		It("routes the path prefix to the management port of the client Service", func() {
+			instance.Spec.Management.Ingress.IngressClassName = pointer.StringPtr("nginx")
+			Expect(ingressBuilder.Update(ingress)).To(Succeed())
+			Expect(ingress.Spec.IngressClassName).To(Equal(pointer.StringPtr("nginx")))
+			Expect(ingress.Spec.Rules).To(HaveLen(1))
+			Expect(ingress.Spec.Rules[0].Host).To(Equal("rabbitmq.example.com"))
+			path := ingress.Spec.Rules[0].HTTP.Paths[0]
+			Expect(path.Path).To(Equal("/rabbitmq"))
+			Expect(*path.PathType).To(Equal(networkingv1.PathTypePrefix))
+			Expect(path.Backend.Service.Name).To(Equal("a-name"))
+			Expect(path.Backend.Service.Port.Number).To(Equal(int32(15672)))
+			Expect(ingress.Spec.TLS).To(BeEmpty())
+			Expect(ingress.OwnerReferences[0].Name).To(Equal("a-name"))
+		})
+
+		It("terminates TLS with the given Secret", func() {
+			instance.Spec.Management.Ingress.TLS = &rabbitmqv1beta1.ManagementIngressTLSSpec{SecretName: "ingress-tls"}
+			Expect(ingressBuilder.Update(ingress)).To(Succeed())
+			Expect(ingress.Spec.TLS).To(ConsistOf(networkingv1.IngressTLS{
+				Hosts:      []string{"rabbitmq.example.com"},
+				SecretName: "ingress-tls",
+			}))
+		})
+
+		It("passes TLS through to the management-tls port", func() {
+			instance.Spec.TLS.SecretName = "rabbitmq-tls"
+			instance.Spec.Management.Ingress.TLS = &rabbitmqv1beta1.ManagementIngressTLSSpec{SecretName: "ingress-tls", Passthrough: true}
+			instance.Spec.Management.Ingress.Annotations = map[string]string{"some": "annotation"}
+			Expect(ingressBuilder.Update(ingress)).To(Succeed())
+			Expect(ingress.Spec.TLS).To(BeEmpty())
+			Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number).To(Equal(int32(15671)))
+			Expect(ingress.Annotations).To(SatisfyAll(
+				HaveKeyWithValue("nginx.ingress.kubernetes.io/ssl-passthrough", "true"),
+				HaveKeyWithValue("nginx.ingress.kubernetes.io/backend-protocol", "HTTPS"),
+				HaveKeyWithValue("some", "annotation"),
+			))
+		})
+	})
+
+	Context("HTTPRoute", func() {
+		var (
+			routeBuilder *resource.ManagementHTTPRouteBuilder
+			route        *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			builder.GatewayAPIInstalled = true
+			routeBuilder = builder.ManagementHTTPRoute()
+			obj, err := routeBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			route = obj.(*unstructured.Unstructured)
+		})
+
+		It("is only needed if the Gateway API is installed", func() {
+			Expect(route.GroupVersionKind()).To(Equal(resource.HTTPRouteGVK))
+			Expect(routeBuilder.Needed()).To(BeTrue())
+			builder.GatewayAPIInstalled = false
+			Expect(routeBuilder.Needed()).To(BeFalse())
+		})
+
+		It("attaches to the Gateway and routes the path prefix to the client Service", func() {
+			instance.Spec.Management.Ingress.Gateway = &rabbitmqv1beta1.ManagementGatewayReference{Name: "gateway", Namespace: "gateway-namespace"}
+			Expect(routeBuilder.Update(route)).To(Succeed())
+
+			parentRefs, _, err := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parentRefs).To(ConsistOf(map[string]interface{}{"name": "gateway", "namespace": "gateway-namespace"}))
+			hostnames, _, err := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostnames).To(ConsistOf("rabbitmq.example.com"))
+
+			rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(HaveLen(1))
+			rule := rules[0].(map[string]interface{})
+			Expect(rule["matches"]).To(ConsistOf(map[string]interface{}{
+				"path": map[string]interface{}{"type": "PathPrefix", "value": "/rabbitmq"},
+			}))
+			Expect(rule["backendRefs"]).To(ConsistOf(map[string]interface{}{"name": "a-name", "port": int64(15672)}))
+			Expect(route.GetOwnerReferences()[0].Name).To(Equal("a-name"))
+		})
+
+		It("requires a Gateway", func() {
+			instance.Spec.Management.Ingress.Gateway = nil
+			Expect(routeBuilder.Update(route)).To(MatchError("spec.management.ingress.gateway is required because the Gateway API is installed"))
+			Expect(route.Object).NotTo(HaveKey("spec"))
+		})
+	})
+
+	Context("ManagementURL", func() {
+		It("returns the URL of the management UI", func() {
+			Expect(resource.ManagementURL(&instance)).To(Equal("http://rabbitmq.example.com/rabbitmq/"))
+			instance.Spec.Management.Ingress.TLS = &rabbitmqv1beta1.ManagementIngressTLSSpec{}
+			instance.Spec.Management.Ingress.PathPrefix = ""
+			Expect(resource.ManagementURL(&instance)).To(Equal("https://rabbitmq.example.com/"))
+			instance.Spec.Management = nil
+			Expect(resource.ManagementURL(&instance)).To(BeEmpty())
+		})
+	})
+})
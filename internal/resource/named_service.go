@@ -0,0 +1,110 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// NamedServiceLabel is set on the Services of spec.services to the name in spec.services.
+// The operator deletes Services with this label which are no longer listed in spec.services.
+const NamedServiceLabel = "rabbitmq.com/service"
+
+// the names of the headless Service and the per Pod Services, also rejected by the validation of spec.services
+var reservedServiceName = regexp.MustCompile(`^(nodes|server-[0-9]+)$`)
+
+type NamedServiceBuilder struct {
+	*RabbitmqResourceBuilder
+	Spec rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec
+}
+
+func (builder *RabbitmqResourceBuilder) NamedService(spec rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec) *NamedServiceBuilder {
+	return &NamedServiceBuilder{builder, spec}
+}
+
+func (builder *NamedServiceBuilder) Build() (client.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(builder.Spec.Name),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *NamedServiceBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+func (builder *NamedServiceBuilder) Update(object client.Object) error {
+	service := object.(*corev1.Service)
+	if reservedServiceName.MatchString(builder.Spec.Name) {
+		return fmt.Errorf("service name %s is reserved", builder.Spec.Name)
+	}
+
+	service.Labels = mergeMap(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels), map[string]string{
+		NamedServiceLabel: builder.Spec.Name,
+	})
+	service.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations), builder.Spec.Annotations)
+
+	service.Spec.Type = builder.Spec.Type
+	if service.Spec.Type == "" {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+	service.Spec.Selector = metadata.LabelSelector(builder.Instance.Name)
+	service.Spec.Ports = exposedPorts(service.Spec.Ports, builder.Service().generateServicePortsMap(), builder.Spec.Listeners)
+	if service.Spec.Type == corev1.ServiceTypeClusterIP {
+		for i := range service.Spec.Ports {
+			service.Spec.Ports[i].NodePort = 0
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, service, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+// exposedPorts returns the given listeners, or all ports if no listeners are given, sorted by port.
+// Node ports allocated to the current ports are kept.
+func exposedPorts(current []corev1.ServicePort, portsMap map[string]corev1.ServicePort, listeners []rabbitmqv1beta1.Listener) []corev1.ServicePort {
+	nodePorts := map[string]int32{}
+	for _, p := range current {
+		nodePorts[p.Name] = p.NodePort
+	}
+
+	if len(listeners) > 0 {
+		selected := map[string]corev1.ServicePort{}
+		for _, l := range listeners {
+			if p, ok := portsMap[string(l)]; ok {
+				selected[string(l)] = p
+			}
+		}
+		portsMap = selected
+	}
+
+	var ports []corev1.ServicePort
+	for _, p := range portsMap {
+		p.NodePort = nodePorts[p.Name]
+		ports = append(ports, p)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	return ports
+}
@@ -0,0 +1,116 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("NamedService", func() {
+	var (
+		instance rabbitmqv1beta1.RabbitmqCluster
+		builder  *resource.RabbitmqResourceBuilder
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+		}
+		instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_mqtt"}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+	})
+
+	build := func(spec rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec) (*resource.NamedServiceBuilder, *corev1.Service) {
+		serviceBuilder := builder.NamedService(spec)
+		obj, err := serviceBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		return serviceBuilder, obj.(*corev1.Service)
+	}
+
+	It("builds a Service named after the RabbitmqCluster and the given name", func() {
+		_, service := build(rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{Name: "amqp-internal"})
+		Expect(service.Name).To(Equal("a-name-amqp-internal"))
+		Expect(service.Namespace).To(Equal("a-namespace"))
+	})
+
+	It("exposes only the chosen listeners", func() {
+		serviceBuilder, service := build(rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{
+			Name:        "mqtt-public",
+			Type:        corev1.ServiceTypeLoadBalancer,
+			Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internet-facing"},
+			Listeners:   []rabbitmqv1beta1.Listener{"mqtt", "mqtts", "stomp"},
+		})
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(service.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-scheme", "internet-facing"))
+		Expect(service.Labels).To(HaveKeyWithValue("rabbitmq.com/service", "mqtt-public"))
+		Expect(service.Labels).NotTo(HaveKey("rabbitmq.com/client-service"))
+		Expect(service.Spec.Selector).To(Equal(map[string]string{"app.kubernetes.io/name": "a-name"}))
+		// mqtts and stomp are not enabled
+		Expect(service.Spec.Ports).To(ConsistOf(corev1.ServicePort{
+			Name:       "mqtt",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       1883,
+			TargetPort: intstr.FromInt(1883),
+		}))
+		Expect(service.OwnerReferences[0].Name).To(Equal("a-name"))
+	})
+
+	It("defaults to a ClusterIP Service exposing all listeners of the client Service", func() {
+		serviceBuilder, service := build(rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{Name: "all"})
+		service.Spec.Ports = []corev1.ServicePort{{Name: "amqp", Port: 5672, NodePort: 30672}}
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		var names []string
+		for _, p := range service.Spec.Ports {
+			names = append(names, p.Name)
+			Expect(p.NodePort).To(BeZero())
+		}
+		Expect(names).To(Equal([]string{"mqtt", "amqp", "management", "prometheus"}))
+	})
+
+	It("keeps allocated node ports", func() {
+		serviceBuilder, service := build(rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{
+			Name:      "amqp-nodeport",
+			Type:      corev1.ServiceTypeNodePort,
+			Listeners: []rabbitmqv1beta1.Listener{"amqp"},
+		})
+		service.Spec.Ports = []corev1.ServicePort{{Name: "amqp", Port: 5672, NodePort: 30672}}
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+		Expect(service.Spec.Ports).To(HaveLen(1))
+		Expect(service.Spec.Ports[0].NodePort).To(Equal(int32(30672)))
+	})
+
+	It("refuses the names of the headless and per Pod Services", func() {
+		for _, name := range []string{"nodes", "server-0"} {
+			serviceBuilder, service := build(rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{Name: name})
+			Expect(serviceBuilder.Update(service)).To(MatchError("service name " + name + " is reserved"))
+		}
+	})
+})
@@ -0,0 +1,129 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	networkPolicyName = "server"
+	// rabbitmqctl and the other CLI tools use one of the eleven ports starting at the distribution port + 10000
+	cliDistributionPortMin = 35672
+	cliDistributionPortMax = 35682
+)
+
+type NetworkPolicyBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) NetworkPolicy() *NetworkPolicyBuilder {
+	return &NetworkPolicyBuilder{builder}
+}
+
+func (builder *NetworkPolicyBuilder) Build() (client.Object, error) {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(networkPolicyName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *NetworkPolicyBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+// Needed returns true if the RabbitmqCluster opts in to a NetworkPolicy
+func (builder *NetworkPolicyBuilder) Needed() bool {
+	return builder.Instance.Spec.NetworkPolicy != nil
+}
+
+func (builder *NetworkPolicyBuilder) Update(object client.Object) error {
+	networkPolicy := object.(*networkingv1.NetworkPolicy)
+	networkPolicy.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	networkPolicy.Annotations = metadata.ReconcileAndFilterAnnotations(networkPolicy.GetAnnotations(), builder.Instance.Annotations)
+
+	clusterPods := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+		},
+	}
+	clientPeers := append([]networkingv1.NetworkPolicyPeer{clusterPods}, builder.Instance.Spec.NetworkPolicy.ClientPeers...)
+
+	networkPolicy.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{
+				Ports: interNodePorts(),
+				From:  []networkingv1.NetworkPolicyPeer{clusterPods},
+			},
+			{
+				Ports: builder.clientPorts(),
+				From:  clientPeers,
+			},
+		},
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, networkPolicy, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+// interNodePorts returns the epmd, Erlang distribution and CLI tools distribution ports
+func interNodePorts() []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{
+		networkPolicyPort(4369),
+		networkPolicyPort(25672),
+	}
+	for port := cliDistributionPortMin; port <= cliDistributionPortMax; port++ {
+		ports = append(ports, networkPolicyPort(port))
+	}
+	return ports
+}
+
+// clientPorts returns the ports of the client Service, which only include TLS ports if non TLS listeners are disabled
+func (builder *NetworkPolicyBuilder) clientPorts() []networkingv1.NetworkPolicyPort {
+	var servicePorts []corev1.ServicePort
+	for _, port := range builder.Service().generateServicePortsMap() {
+		servicePorts = append(servicePorts, port)
+	}
+	sort.Slice(servicePorts, func(i, j int) bool {
+		return servicePorts[i].Port < servicePorts[j].Port
+	})
+
+	var ports []networkingv1.NetworkPolicyPort
+	for _, port := range servicePorts {
+		ports = append(ports, networkPolicyPort(port.TargetPort.IntValue()))
+	}
+	return ports
+}
+
+func networkPolicyPort(port int) networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	p := intstr.FromInt(port)
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &p,
+	}
+}
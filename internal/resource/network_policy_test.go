@@ -0,0 +1,133 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("NetworkPolicy", func() {
+	var (
+		networkPolicy        *networkingv1.NetworkPolicy
+		instance             rabbitmqv1beta1.RabbitmqCluster
+		networkPolicyBuilder *resource.NetworkPolicyBuilder
+		builder              *resource.RabbitmqResourceBuilder
+		scheme               *runtime.Scheme
+		monitoringNamespace  = networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "monitoring"}},
+		}
+	)
+
+	ports := func(rule networkingv1.NetworkPolicyIngressRule) []int {
+		var ports []int
+		for _, p := range rule.Ports {
+			ports = append(ports, p.Port.IntValue())
+		}
+		return ports
+	}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				NetworkPolicy: &rabbitmqv1beta1.RabbitmqClusterNetworkPolicySpec{
+					ClientPeers: []networkingv1.NetworkPolicyPeer{monitoringNamespace},
+				},
+			},
+		}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		networkPolicyBuilder = builder.NetworkPolicy()
+		networkPolicy = &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "a-namespace"},
+		}
+	})
+
+	Context("Build", func() {
+		It("generates a NetworkPolicy with the correct name and namespace", func() {
+			obj, err := networkPolicyBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.GetName()).To(Equal("a-name-server"))
+			Expect(obj.GetNamespace()).To(Equal("a-namespace"))
+		})
+	})
+
+	Context("Needed", func() {
+		It("returns true if the RabbitmqCluster sets a NetworkPolicy", func() {
+			Expect(networkPolicyBuilder.Needed()).To(BeTrue())
+		})
+
+		It("returns false otherwise", func() {
+			instance.Spec.NetworkPolicy = nil
+			Expect(networkPolicyBuilder.Needed()).To(BeFalse())
+		})
+	})
+
+	Context("Update", func() {
+		It("selects the RabbitMQ Pods and sets the owner reference", func() {
+			Expect(networkPolicyBuilder.Update(networkPolicy)).To(Succeed())
+			Expect(networkPolicy.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "a-name"}))
+			Expect(networkPolicy.Spec.PolicyTypes).To(ConsistOf(networkingv1.PolicyTypeIngress))
+			Expect(networkPolicy.OwnerReferences[0].Name).To(Equal(instance.Name))
+		})
+
+		It("allows inter-node traffic only between Pods of the cluster", func() {
+			Expect(networkPolicyBuilder.Update(networkPolicy)).To(Succeed())
+			interNode := networkPolicy.Spec.Ingress[0]
+			Expect(ports(interNode)).To(Equal([]int{4369, 25672, 35672, 35673, 35674, 35675, 35676, 35677, 35678, 35679, 35680, 35681, 35682}))
+			Expect(interNode.From).To(HaveLen(1))
+			Expect(interNode.From[0].PodSelector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "a-name"}))
+		})
+
+		It("allows the client ports from the client peers", func() {
+			Expect(networkPolicyBuilder.Update(networkPolicy)).To(Succeed())
+			clients := networkPolicy.Spec.Ingress[1]
+			Expect(ports(clients)).To(Equal([]int{5672, 15672, 15692}))
+			Expect(clients.From).To(HaveLen(2))
+			Expect(clients.From[1]).To(Equal(monitoringNamespace))
+		})
+
+		It("allows the client ports of enabled plugins", func() {
+			instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_mqtt", "rabbitmq_stream"}
+			Expect(networkPolicyBuilder.Update(networkPolicy)).To(Succeed())
+			Expect(ports(networkPolicy.Spec.Ingress[1])).To(Equal([]int{1883, 5552, 5672, 15672, 15692}))
+		})
+
+		It("allows only TLS client ports if non TLS listeners are disabled", func() {
+			instance.Spec.TLS = rabbitmqv1beta1.TLSSpec{
+				SecretName:             "tls-secret",
+				DisableNonTLSListeners: true,
+			}
+			Expect(networkPolicyBuilder.Update(networkPolicy)).To(Succeed())
+			Expect(ports(networkPolicy.Spec.Ingress[1])).To(Equal([]int{5671, 15671, 15691}))
+		})
+	})
+
+	Context("UpdateMayRequireStsRecreate", func() {
+		It("returns false", func() {
+			Expect(networkPolicyBuilder.UpdateMayRequireStsRecreate()).To(BeFalse())
+		})
+	})
+})
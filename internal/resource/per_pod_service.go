@@ -0,0 +1,79 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PerPodServiceLabel is set on the per Pod Services to the name of the Pod, so that they can be told apart from the other Services of the RabbitmqCluster.
+const PerPodServiceLabel = "rabbitmq.com/per-pod-service"
+
+// PerPodServiceBuilder builds the Service of the Pod with the given ordinal. The Service has the name of the Pod.
+type PerPodServiceBuilder struct {
+	*RabbitmqResourceBuilder
+	Index int32
+}
+
+func (builder *RabbitmqResourceBuilder) PerPodService(index int32) *PerPodServiceBuilder {
+	return &PerPodServiceBuilder{builder, index}
+}
+
+func (builder *PerPodServiceBuilder) podName() string {
+	return fmt.Sprintf("%s-%d", builder.Instance.ChildResourceName("server"), builder.Index)
+}
+
+func (builder *PerPodServiceBuilder) Build() (client.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.podName(),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *PerPodServiceBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+func (builder *PerPodServiceBuilder) Needed() bool {
+	return builder.Instance.Spec.PerPodService != nil
+}
+
+func (builder *PerPodServiceBuilder) Update(object client.Object) error {
+	service := object.(*corev1.Service)
+	spec := builder.Instance.Spec.PerPodService
+
+	service.Labels = mergeMap(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels), map[string]string{
+		PerPodServiceLabel: builder.podName(),
+	})
+	service.Annotations = metadata.ReconcileAnnotations(metadata.ReconcileAndFilterAnnotations(service.Annotations, builder.Instance.Annotations), spec.Annotations)
+
+	service.Spec.Type = spec.Type
+	if service.Spec.Type == "" {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+	service.Spec.Selector = map[string]string{
+		appsv1.StatefulSetPodNameLabel: builder.podName(),
+	}
+	service.Spec.Ports = exposedPorts(service.Spec.Ports, builder.Service().generateServicePortsMap(), nil)
+
+	if err := controllerutil.SetControllerReference(builder.Instance, service, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
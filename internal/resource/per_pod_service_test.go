@@ -0,0 +1,99 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("PerPodService", func() {
+	var (
+		instance       rabbitmqv1beta1.RabbitmqCluster
+		serviceBuilder *resource.PerPodServiceBuilder
+		service        *corev1.Service
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				PerPodService: &rabbitmqv1beta1.RabbitmqClusterPerPodServiceSpec{},
+			},
+		}
+		builder := &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		serviceBuilder = builder.PerPodService(1)
+		obj, err := serviceBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		service = obj.(*corev1.Service)
+	})
+
+	It("builds a Service with the name of the Pod", func() {
+		Expect(service.Name).To(Equal("a-name-server-1"))
+		Expect(service.Namespace).To(Equal("a-namespace"))
+	})
+
+	It("is only needed if spec.perPodService is set", func() {
+		Expect(serviceBuilder.Needed()).To(BeTrue())
+		instance.Spec.PerPodService = nil
+		Expect(serviceBuilder.Needed()).To(BeFalse())
+	})
+
+	It("defaults to a LoadBalancer Service selecting the Pod", func() {
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(service.Spec.Selector).To(Equal(map[string]string{"statefulset.kubernetes.io/pod-name": "a-name-server-1"}))
+		Expect(service.OwnerReferences[0].Name).To(Equal("a-name"))
+	})
+
+	It("labels the Service with the name of the Pod and not as the client Service", func() {
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+		Expect(service.Labels).To(HaveKeyWithValue("rabbitmq.com/per-pod-service", "a-name-server-1"))
+		Expect(service.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "a-name"))
+		Expect(service.Labels).NotTo(HaveKey("rabbitmq.com/client-service"))
+	})
+
+	It("exposes the ports of the client Service, including the stream port", func() {
+		instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_stream"}
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+		var names []string
+		for _, p := range service.Spec.Ports {
+			names = append(names, p.Name)
+		}
+		Expect(names).To(Equal([]string{"stream", "amqp", "management", "prometheus"}))
+	})
+
+	It("keeps allocated node ports", func() {
+		instance.Spec.PerPodService.Type = corev1.ServiceTypeNodePort
+		instance.Spec.PerPodService.Annotations = map[string]string{"some": "annotation"}
+		service.Spec.Ports = []corev1.ServicePort{{Name: "amqp", Port: 5672, NodePort: 30672}}
+		Expect(serviceBuilder.Update(service)).To(Succeed())
+		Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+		Expect(service.Annotations).To(HaveKeyWithValue("some", "annotation"))
+		Expect(service.Spec.Ports[0].Name).To(Equal("amqp"))
+		Expect(service.Spec.Ports[0].NodePort).To(Equal(int32(30672)))
+		Expect(service.Spec.Ports[1].NodePort).To(BeZero())
+	})
+})
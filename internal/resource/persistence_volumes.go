@@ -0,0 +1,105 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const defaultPersistenceVolumeStorage = "10Gi"
+
+func persistenceVolumeStorage(volume rabbitmqv1beta1.PersistenceVolume) k8sresource.Quantity {
+	if volume.Storage == nil {
+		return k8sresource.MustParse(defaultPersistenceVolumeStorage)
+	}
+	return *volume.Storage
+}
+
+func persistenceVolumeMountPath(role rabbitmqv1beta1.PersistenceVolumeRole) string {
+	switch role {
+	case rabbitmqv1beta1.PersistenceVolumeLogs:
+		return "/var/log/rabbitmq/"
+	case rabbitmqv1beta1.PersistenceVolumeStreamSegments:
+		return "/var/lib/rabbitmq/stream/"
+	default:
+		return fmt.Sprintf("/var/lib/rabbitmq/%s/", role)
+	}
+}
+
+func persistenceVolumeMounts(volumes []rabbitmqv1beta1.PersistenceVolume) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	for _, v := range volumes {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      string(v.Role),
+			MountPath: persistenceVolumeMountPath(v.Role),
+		})
+	}
+	return mounts
+}
+
+// persistenceVolumeEnvVars returns the environment variables which configure RabbitMQ to store data on the additional persistent volumes.
+func persistenceVolumeEnvVars(volumes []rabbitmqv1beta1.PersistenceVolume) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	for _, v := range volumes {
+		path := persistenceVolumeMountPath(v.Role)
+		switch v.Role {
+		case rabbitmqv1beta1.PersistenceVolumeQuorumSegments:
+			envVars = append(envVars, corev1.EnvVar{Name: "RABBITMQ_QUORUM_DIR", Value: path})
+		case rabbitmqv1beta1.PersistenceVolumeStreamSegments:
+			envVars = append(envVars, corev1.EnvVar{Name: "RABBITMQ_STREAM_DIR", Value: path})
+		case rabbitmqv1beta1.PersistenceVolumeLogs:
+			// overrides RABBITMQ_LOGS=- of the RabbitMQ image, which logs to the console only
+			envVars = append(envVars,
+				corev1.EnvVar{Name: "RABBITMQ_LOG_BASE", Value: path},
+				corev1.EnvVar{Name: "RABBITMQ_LOGS", Value: path + "rabbit.log"},
+			)
+		}
+	}
+	return envVars
+}
+
+// envConfig returns the content of rabbitmq-env.conf: spec.rabbitmq.envConfig followed by the settings of the additional persistent volumes
+// and of the zone of the node. There are no environment variables for the Ra write-ahead log directory and the zone;
+// they are set as application environment of ra and rabbit instead. The arguments are appended to the additional Erlang arguments
+// set in spec.rabbitmq.envConfig or in the environment of the container.
+func envConfig(instance *rabbitmqv1beta1.RabbitmqCluster) string {
+	var erlArgs []string
+	if persistenceVolumeEnabled(instance.Spec.Persistence.Volumes, rabbitmqv1beta1.PersistenceVolumeQuorumWAL) {
+		erlArgs = append(erlArgs, fmt.Sprintf(`-ra wal_data_dir \"%s\"`, persistenceVolumeMountPath(rabbitmqv1beta1.PersistenceVolumeQuorumWAL)))
+	}
+	if instance.Spec.Topology != nil {
+		// rabbitmq-env.conf is sourced by the startup script of RabbitMQ, which reads the zone when the node starts
+		erlArgs = append(erlArgs, fmt.Sprintf(`-rabbit zone \"$(cat %s)\"`, zoneFile))
+	}
+
+	userEnvConfig := instance.Spec.Rabbitmq.EnvConfig
+	if len(erlArgs) == 0 {
+		return userEnvConfig
+	}
+	lines := []string{fmt.Sprintf(`RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS="${RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS:-$SERVER_ADDITIONAL_ERL_ARGS} %s"`, strings.Join(erlArgs, " "))}
+	if userEnvConfig != "" {
+		lines = append([]string{strings.TrimSuffix(userEnvConfig, "\n")}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func persistenceVolumeEnabled(volumes []rabbitmqv1beta1.PersistenceVolume, role rabbitmqv1beta1.PersistenceVolumeRole) bool {
+	for _, v := range volumes {
+		if v.Role == role {
+			return true
+		}
+	}
+	return false
+}
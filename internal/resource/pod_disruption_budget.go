@@ -0,0 +1,102 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	podDisruptionBudgetName = "server"
+)
+
+type PodDisruptionBudgetBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) PodDisruptionBudget() *PodDisruptionBudgetBuilder {
+	return &PodDisruptionBudgetBuilder{builder}
+}
+
+func (builder *PodDisruptionBudgetBuilder) Build() (client.Object, error) {
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builder.Instance.ChildResourceName(podDisruptionBudgetName),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *PodDisruptionBudgetBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+// Needed returns false for single node clusters: a PodDisruptionBudget would block node drains forever.
+func (builder *PodDisruptionBudgetBuilder) Needed() bool {
+	return builder.Instance.Spec.Replicas != nil && *builder.Instance.Spec.Replicas > 1
+}
+
+func (builder *PodDisruptionBudgetBuilder) Update(object client.Object) error {
+	pdb := object.(*policyv1beta1.PodDisruptionBudget)
+	pdb.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	pdb.Annotations = metadata.ReconcileAndFilterAnnotations(pdb.GetAnnotations(), builder.Instance.Annotations)
+
+	maxUnavailable := intstr.FromInt(1)
+	pdb.Spec = policyv1beta1.PodDisruptionBudgetSpec{
+		MaxUnavailable: &maxUnavailable,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+		},
+	}
+
+	if builder.Instance.Spec.Override.PodDisruptionBudget != nil {
+		if err := applyPDBOverride(pdb, builder.Instance.Spec.Override.PodDisruptionBudget); err != nil {
+			return err
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, pdb, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+func applyPDBOverride(pdb *policyv1beta1.PodDisruptionBudget, override *rabbitmqv1beta1.PodDisruptionBudget) error {
+	if override.EmbeddedLabelsAnnotations != nil {
+		copyLabelsAnnotations(&pdb.ObjectMeta, *override.EmbeddedLabelsAnnotations)
+	}
+
+	if override.Spec == nil {
+		return nil
+	}
+	// minAvailable and maxUnavailable are mutually exclusive
+	if override.Spec.MinAvailable != nil && override.Spec.MaxUnavailable != nil {
+		return fmt.Errorf("override.podDisruptionBudget.spec must not set both minAvailable and maxUnavailable")
+	}
+	if override.Spec.MinAvailable != nil {
+		pdb.Spec.MinAvailable = override.Spec.MinAvailable
+		pdb.Spec.MaxUnavailable = nil
+	}
+	if override.Spec.MaxUnavailable != nil {
+		pdb.Spec.MaxUnavailable = override.Spec.MaxUnavailable
+	}
+	if override.Spec.Selector != nil {
+		pdb.Spec.Selector = override.Spec.Selector
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("PodDisruptionBudget", func() {
+	var (
+		pdb        *policyv1beta1.PodDisruptionBudget
+		instance   rabbitmqv1beta1.RabbitmqCluster
+		pdbBuilder *resource.PodDisruptionBudgetBuilder
+		builder    *resource.RabbitmqResourceBuilder
+		scheme     *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+			Spec: rabbitmqv1beta1.RabbitmqClusterSpec{
+				Replicas: pointer.Int32Ptr(3),
+			},
+		}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance: &instance,
+			Scheme:   scheme,
+		}
+		pdbBuilder = builder.PodDisruptionBudget()
+	})
+
+	Context("Build", func() {
+		It("generates a PodDisruptionBudget with the correct name and namespace", func() {
+			obj, err := pdbBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			pdb = obj.(*policyv1beta1.PodDisruptionBudget)
+			Expect(pdb.Name).To(Equal("a-name-server"))
+			Expect(pdb.Namespace).To(Equal("a-namespace"))
+		})
+	})
+
+	Context("Needed", func() {
+		It("returns true for clusters with more than one replica", func() {
+			Expect(pdbBuilder.Needed()).To(BeTrue())
+		})
+
+		It("returns false for single node clusters", func() {
+			instance.Spec.Replicas = pointer.Int32Ptr(1)
+			Expect(pdbBuilder.Needed()).To(BeFalse())
+		})
+	})
+
+	Context("Update", func() {
+		BeforeEach(func() {
+			pdb = &policyv1beta1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "a-namespace",
+					Labels: map[string]string{
+						"this-was-the-previous-label": "should-be-deleted",
+					},
+				},
+			}
+		})
+
+		It("allows one unavailable Pod of the cluster", func() {
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+			Expect(pdb.Spec.MaxUnavailable).To(Equal(&intstr.IntOrString{Type: intstr.Int, IntVal: 1}))
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "a-name"}))
+		})
+
+		It("sets labels and owner reference", func() {
+			Expect(pdbBuilder.Update(pdb)).To(Succeed())
+			Expect(pdb.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "a-name"))
+			Expect(pdb.Labels).NotTo(HaveKey("this-was-the-previous-label"))
+			Expect(pdb.OwnerReferences[0].Name).To(Equal(instance.Name))
+		})
+
+		Context("override", func() {
+			It("overrides labels, annotations and maxUnavailable", func() {
+				maxUnavailable := intstr.FromString("50%")
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					EmbeddedLabelsAnnotations: &rabbitmqv1beta1.EmbeddedLabelsAnnotations{
+						Labels:      map[string]string{"new-label": "new-value"},
+						Annotations: map[string]string{"new-annotation": "new-value"},
+					},
+					Spec: &policyv1beta1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+				Expect(pdb.Labels).To(HaveKeyWithValue("new-label", "new-value"))
+				Expect(pdb.Annotations).To(HaveKeyWithValue("new-annotation", "new-value"))
+				Expect(pdb.Spec.MaxUnavailable).To(Equal(&maxUnavailable))
+			})
+
+			It("replaces maxUnavailable with minAvailable", func() {
+				minAvailable := intstr.FromInt(2)
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					Spec: &policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(Succeed())
+				Expect(pdb.Spec.MinAvailable).To(Equal(&minAvailable))
+				Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+			})
+
+			It("rejects both minAvailable and maxUnavailable", func() {
+				minAvailable := intstr.FromInt(2)
+				maxUnavailable := intstr.FromInt(1)
+				instance.Spec.Override.PodDisruptionBudget = &rabbitmqv1beta1.PodDisruptionBudget{
+					Spec: &policyv1beta1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable, MaxUnavailable: &maxUnavailable},
+				}
+				Expect(pdbBuilder.Update(pdb)).To(MatchError("override.podDisruptionBudget.spec must not set both minAvailable and maxUnavailable"))
+			})
+		})
+	})
+
+	Context("UpdateMayRequireStsRecreate", func() {
+		It("returns false", func() {
+			Expect(pdbBuilder.UpdateMayRequireStsRecreate()).To(BeFalse())
+		})
+	})
+})
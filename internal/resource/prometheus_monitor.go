@@ -0,0 +1,152 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// The Prometheus Operator types are not imported to avoid a dependency on the Prometheus Operator module.
+// The monitors are built as unstructured objects; reconciling them fails with a NoMatch error if the CRDs are not installed.
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+)
+
+const (
+	monitorName          = ""
+	monitorInterval      = "15s"
+	monitorScrapeTimeout = "14s"
+)
+
+type ServiceMonitorBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) ServiceMonitor() *ServiceMonitorBuilder {
+	return &ServiceMonitorBuilder{builder}
+}
+
+func (builder *ServiceMonitorBuilder) Build() (client.Object, error) {
+	return newMonitor(builder.Instance, ServiceMonitorGVK), nil
+}
+
+func (builder *ServiceMonitorBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+func (builder *ServiceMonitorBuilder) Needed() bool {
+	return builder.PrometheusOperatorInstalled && builder.Instance.MonitorKind() == rabbitmqv1beta1.ServiceMonitorKind
+}
+
+// Update selects the client Service, which exposes either the prometheus or the prometheus-tls port.
+// The per Pod Services and the Services of spec.services expose the same ports and are not selected, so that each node is scraped once.
+func (builder *ServiceMonitorBuilder) Update(object client.Object) error {
+	monitor := object.(*unstructured.Unstructured)
+	monitor.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{scrapeEndpoint(builder.Instance)},
+		"selector": map[string]interface{}{
+			"matchLabels": stringMap(mergeMap(metadata.GetLabels(builder.Instance.Name, nil), map[string]string{
+				ClientServiceLabel: "true",
+			})),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{builder.Instance.Namespace},
+		},
+	}
+	return updateMonitorMetadata(builder.RabbitmqResourceBuilder, monitor)
+}
+
+type PodMonitorBuilder struct {
+	*RabbitmqResourceBuilder
+}
+
+func (builder *RabbitmqResourceBuilder) PodMonitor() *PodMonitorBuilder {
+	return &PodMonitorBuilder{builder}
+}
+
+func (builder *PodMonitorBuilder) Build() (client.Object, error) {
+	return newMonitor(builder.Instance, PodMonitorGVK), nil
+}
+
+func (builder *PodMonitorBuilder) UpdateMayRequireStsRecreate() bool {
+	return false
+}
+
+func (builder *PodMonitorBuilder) Needed() bool {
+	return builder.PrometheusOperatorInstalled && builder.Instance.MonitorKind() == rabbitmqv1beta1.PodMonitorKind
+}
+
+func (builder *PodMonitorBuilder) Update(object client.Object) error {
+	monitor := object.(*unstructured.Unstructured)
+	monitor.Object["spec"] = map[string]interface{}{
+		"podMetricsEndpoints": []interface{}{scrapeEndpoint(builder.Instance)},
+		"selector": map[string]interface{}{
+			"matchLabels": stringMap(metadata.LabelSelector(builder.Instance.Name)),
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{builder.Instance.Namespace},
+		},
+	}
+	return updateMonitorMetadata(builder.RabbitmqResourceBuilder, monitor)
+}
+
+func newMonitor(instance *rabbitmqv1beta1.RabbitmqCluster, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(gvk)
+	monitor.SetName(instance.ChildResourceName(monitorName))
+	monitor.SetNamespace(instance.Namespace)
+	return monitor
+}
+
+// scrapeEndpoint returns the prometheus-tls port if TLS is enabled, like the prometheus.io/port Pod annotation.
+// The certificate is not verified because it is usually issued for the Service and not for the Pod IP addresses.
+func scrapeEndpoint(instance *rabbitmqv1beta1.RabbitmqCluster) map[string]interface{} {
+	endpoint := map[string]interface{}{
+		"port":          "prometheus",
+		"scheme":        "http",
+		"interval":      monitorInterval,
+		"scrapeTimeout": monitorScrapeTimeout,
+	}
+	if instance.TLSEnabled() {
+		endpoint["port"] = "prometheus-tls"
+		endpoint["scheme"] = "https"
+		endpoint["tlsConfig"] = map[string]interface{}{
+			"insecureSkipVerify": true,
+		}
+	}
+	return endpoint
+}
+
+func updateMonitorMetadata(builder *RabbitmqResourceBuilder, monitor *unstructured.Unstructured) error {
+	monitor.SetLabels(mergeMap(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels), builder.Instance.Spec.Monitoring.Labels))
+	monitor.SetAnnotations(metadata.ReconcileAndFilterAnnotations(monitor.GetAnnotations(), builder.Instance.Annotations))
+
+	if err := controllerutil.SetControllerReference(builder.Instance, monitor, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %v", err)
+	}
+	return nil
+}
+
+// stringMap converts labels to the map type of unstructured objects
+func stringMap(labels map[string]string) map[string]interface{} {
+	m := make(map[string]interface{}, len(labels))
+	for k, v := range labels {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,152 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	defaultscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("Prometheus Operator monitors", func() {
+	var (
+		instance rabbitmqv1beta1.RabbitmqCluster
+		builder  *resource.RabbitmqResourceBuilder
+		scheme   *runtime.Scheme
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(rabbitmqv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(defaultscheme.AddToScheme(scheme)).To(Succeed())
+		instance = rabbitmqv1beta1.RabbitmqCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-name",
+				Namespace: "a-namespace",
+			},
+		}
+		builder = &resource.RabbitmqResourceBuilder{
+			Instance:                    &instance,
+			Scheme:                      scheme,
+			PrometheusOperatorInstalled: true,
+		}
+	})
+
+	endpoint := func(monitor *unstructured.Unstructured, field string) map[string]interface{} {
+		endpoints, found, err := unstructured.NestedSlice(monitor.Object, "spec", field)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(endpoints).To(HaveLen(1))
+		return endpoints[0].(map[string]interface{})
+	}
+
+	Context("ServiceMonitor", func() {
+		var (
+			monitorBuilder *resource.ServiceMonitorBuilder
+			monitor        *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			monitorBuilder = builder.ServiceMonitor()
+			obj, err := monitorBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			monitor = obj.(*unstructured.Unstructured)
+		})
+
+		It("builds a ServiceMonitor with the name and namespace of the RabbitmqCluster", func() {
+			Expect(monitor.GroupVersionKind()).To(Equal(resource.ServiceMonitorGVK))
+			Expect(monitor.GetName()).To(Equal("a-name"))
+			Expect(monitor.GetNamespace()).To(Equal("a-namespace"))
+		})
+
+		It("is needed by default", func() {
+			Expect(monitorBuilder.Needed()).To(BeTrue())
+			instance.Spec.Monitoring.Kind = rabbitmqv1beta1.PodMonitorKind
+			Expect(monitorBuilder.Needed()).To(BeFalse())
+			instance.Spec.Monitoring.Kind = rabbitmqv1beta1.NoMonitorKind
+			Expect(monitorBuilder.Needed()).To(BeFalse())
+		})
+
+		It("is not needed if the Prometheus Operator CRDs are not installed", func() {
+			builder.PrometheusOperatorInstalled = false
+			Expect(monitorBuilder.Needed()).To(BeFalse())
+		})
+
+		It("scrapes the prometheus port of the client Service", func() {
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+			Expect(endpoint(monitor, "endpoints")).To(Equal(map[string]interface{}{
+				"port":          "prometheus",
+				"scheme":        "http",
+				"interval":      "15s",
+				"scrapeTimeout": "14s",
+			}))
+			selector, _, err := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(HaveKeyWithValue("app.kubernetes.io/name", "a-name"))
+			Expect(selector).To(HaveKeyWithValue("rabbitmq.com/client-service", "true"))
+			namespaces, _, err := unstructured.NestedStringSlice(monitor.Object, "spec", "namespaceSelector", "matchNames")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(ConsistOf("a-namespace"))
+		})
+
+		It("scrapes the prometheus-tls port if TLS is enabled", func() {
+			instance.Spec.TLS.SecretName = "tls-secret"
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+			Expect(endpoint(monitor, "endpoints")).To(SatisfyAll(
+				HaveKeyWithValue("port", "prometheus-tls"),
+				HaveKeyWithValue("scheme", "https"),
+				HaveKeyWithValue("tlsConfig", map[string]interface{}{"insecureSkipVerify": true}),
+			))
+		})
+
+		It("adds the configured labels and the owner reference", func() {
+			instance.Spec.Monitoring.Labels = map[string]string{"release": "prometheus"}
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+			Expect(monitor.GetLabels()).To(HaveKeyWithValue("release", "prometheus"))
+			Expect(monitor.GetLabels()).To(HaveKeyWithValue("app.kubernetes.io/name", "a-name"))
+			Expect(monitor.GetOwnerReferences()[0].Name).To(Equal("a-name"))
+		})
+	})
+
+	Context("PodMonitor", func() {
+		var (
+			monitorBuilder *resource.PodMonitorBuilder
+			monitor        *unstructured.Unstructured
+		)
+
+		BeforeEach(func() {
+			instance.Spec.Monitoring.Kind = rabbitmqv1beta1.PodMonitorKind
+			monitorBuilder = builder.PodMonitor()
+			obj, err := monitorBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			monitor = obj.(*unstructured.Unstructured)
+		})
+
+		It("is only needed if selected", func() {
+			Expect(monitorBuilder.Needed()).To(BeTrue())
+			instance.Spec.Monitoring.Kind = ""
+			Expect(monitorBuilder.Needed()).To(BeFalse())
+		})
+
+		It("scrapes the prometheus port of the RabbitMQ Pods", func() {
+			Expect(monitor.GroupVersionKind()).To(Equal(resource.PodMonitorGVK))
+			Expect(monitorBuilder.Update(monitor)).To(Succeed())
+			Expect(endpoint(monitor, "podMetricsEndpoints")).To(HaveKeyWithValue("port", "prometheus"))
+			selector, _, err := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(selector).To(Equal(map[string]string{"app.kubernetes.io/name": "a-name"}))
+		})
+	})
+})
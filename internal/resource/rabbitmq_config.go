@@ -0,0 +1,389 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"gopkg.in/ini.v1"
+)
+
+type configValueType struct {
+	description string
+	valid       func(value string) bool
+}
+
+var (
+	integerValue = configValueType{"an integer", func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}}
+	integerOrInfinityValue = configValueType{"an integer or infinity", func(value string) bool {
+		return value == "infinity" || integerValue.valid(value)
+	}}
+	numberValue = configValueType{"a number", func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}}
+	booleanValue = configValueType{"true or false", func(value string) bool {
+		return value == "true" || value == "false"
+	}}
+	stringValue = configValueType{"a non-empty string", func(value string) bool {
+		return value != ""
+	}}
+	// information units as accepted by rabbitmq.conf, e.g. 2GB, 500MiB or 1000000
+	bytesValue    = configValueType{"an amount of bytes, e.g. 2GB", regexp.MustCompile(`^[0-9]+([kKmMgGtTpP][iI]?[bB]?)?$`).MatchString}
+	logLevelValue = enumValue("debug", "info", "warning", "error", "critical", "none")
+)
+
+func enumValue(values ...string) configValueType {
+	return configValueType{
+		description: "one of " + strings.Join(values, ", "),
+		valid: func(value string) bool {
+			for _, v := range values {
+				if v == value {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// rabbitmqConfigSchema lists the rabbitmq.conf settings known to the operator and their value types.
+// A * in a key matches a single segment of the key, e.g. log.*.level matches log.console.level.
+var rabbitmqConfigSchema = map[string]configValueType{
+	"auth_backends.*":                                      stringValue,
+	"auth_mechanisms.*":                                    stringValue,
+	"background_gc_enabled":                                booleanValue,
+	"background_gc_target_interval":                        integerValue,
+	"channel_max":                                          integerValue,
+	"channel_operation_timeout":                            integerValue,
+	"classic_queue.default_version":                        integerValue,
+	"cluster_keepalive_interval":                           integerValue,
+	"cluster_partition_handling":                           enumValue("ignore", "autoheal", "pause_minority", "pause_if_all_down"),
+	"cluster_partition_handling.pause_if_all_down.recover": enumValue("ignore", "autoheal"),
+	"cluster_partition_handling.pause_if_all_down.nodes.*": stringValue,
+	"collect_statistics_interval":                          integerValue,
+	"connection_max":                                       integerOrInfinityValue,
+	"consumer_timeout":                                     integerValue,
+	"default_permissions.configure":                        stringValue,
+	"default_permissions.read":                             stringValue,
+	"default_permissions.write":                            stringValue,
+	"default_user_tags.*":                                  booleanValue,
+	"default_vhost":                                        stringValue,
+	"deprecated_features.permit.*":                         booleanValue,
+	"disk_free_limit.absolute":                             bytesValue,
+	"disk_free_limit.relative":                             numberValue,
+	"frame_max":                                            integerValue,
+	"handshake_timeout":                                    integerValue,
+	"heartbeat":                                            integerValue,
+	"load_definitions":                                     stringValue,
+	"log.*.level":                                          logLevelValue,
+	"log.console":                                          booleanValue,
+	"log.console.formatter":                                enumValue("plaintext", "json"),
+	"log.exchange":                                         booleanValue,
+	"log.file.formatter":                                   enumValue("plaintext", "json"),
+	"log.file.rotation.count":                              integerValue,
+	"log.file.rotation.date":                               stringValue,
+	"log.file.rotation.size":                               integerValue,
+	"loopback_users.*":                                     booleanValue,
+	"management.cors.allow_origins.*":                      stringValue,
+	"management.disable_stats":                             booleanValue,
+	"management.load_definitions":                          stringValue,
+	"management.rates_mode":                                enumValue("basic", "detailed", "none"),
+	"management_agent.disable_metrics_collector":           booleanValue,
+	"max_message_size":                                     integerValue,
+	"memory_monitor_interval":                              integerValue,
+	"mirroring_sync_batch_size":                            integerValue,
+	"mqtt.allow_anonymous":                                 booleanValue,
+	"mqtt.default_pass":                                    stringValue,
+	"mqtt.default_user":                                    stringValue,
+	"mqtt.exchange":                                        stringValue,
+	"mqtt.prefetch":                                        integerValue,
+	"mqtt.vhost":                                           stringValue,
+	"prometheus.path":                                      stringValue,
+	"prometheus.return_per_object_metrics":                 booleanValue,
+	"proxy_protocol":                                       booleanValue,
+	"queue_index_embed_msgs_below":                         integerValue,
+	"queue_master_locator":                                 enumValue("min-masters", "client-local", "random"),
+	"reverse_dns_lookups":                                  booleanValue,
+	"stomp.default_pass":                                   stringValue,
+	"stomp.default_user":                                   stringValue,
+	"stomp.implicit_connect":                               booleanValue,
+	"stream.credits_required_for_unblocking":               integerValue,
+	"stream.frame_max":                                     integerValue,
+	"stream.heartbeat":                                     integerValue,
+	"stream.initial_credits":                               integerValue,
+	"tcp_listen_options.backlog":                           integerValue,
+	"tcp_listen_options.keepalive":                         booleanValue,
+	"tcp_listen_options.nodelay":                           booleanValue,
+	"tcp_listen_options.recbuf":                            integerValue,
+	"tcp_listen_options.sndbuf":                            integerValue,
+	"vm_memory_calculation_strategy":                       enumValue("rss", "allocated", "legacy", "erlang"),
+	"vm_memory_high_watermark.absolute":                    bytesValue,
+	"vm_memory_high_watermark.relative":                    numberValue,
+}
+
+// operatorManagedConfigKeys are set by the operator and must not be overridden, since the operator relies on them
+// for peer discovery, the listeners exposed by the Services, TLS, the default user and the memory limit.
+// Patterns ending in a . match all keys with that prefix.
+var operatorManagedConfigKeys = []string{
+	"cluster_formation.",
+	"cluster_name",
+	"default_pass",
+	"default_user",
+	"listeners.",
+	"*.listeners.",
+	"management.path_prefix",
+	"*.tcp.port",
+	"*.tcp.listener",
+	"*.ssl.port",
+	"*.ssl.certfile",
+	"*.ssl.keyfile",
+	"*.ssl.cacertfile",
+	"ssl_options.certfile",
+	"ssl_options.keyfile",
+	"ssl_options.cacertfile",
+	"total_memory_available_override_value",
+}
+
+func operatorManagedConfigKey(key string) bool {
+	for _, pattern := range operatorManagedConfigKeys {
+		if configKeyMatches(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// configKeyMatches matches keys segment by segment. A * matches a single segment.
+func configKeyMatches(pattern, key string) bool {
+	prefix := strings.HasSuffix(pattern, ".")
+	patternSegments := strings.Split(strings.TrimSuffix(pattern, "."), ".")
+	keySegments := strings.Split(key, ".")
+	if prefix && len(keySegments) <= len(patternSegments) {
+		return false
+	}
+	if !prefix && len(keySegments) != len(patternSegments) {
+		return false
+	}
+	for i := range patternSegments {
+		if patternSegments[i] != "*" && patternSegments[i] != keySegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func configValueTypeOf(key string) (configValueType, bool) {
+	if valueType, ok := rabbitmqConfigSchema[key]; ok {
+		return valueType, true
+	}
+	for pattern, valueType := range rabbitmqConfigSchema {
+		if strings.Contains(pattern, "*") && configKeyMatches(pattern, key) {
+			return valueType, true
+		}
+	}
+	return configValueType{}, false
+}
+
+// validateConfig rejects settings of spec.rabbitmq.config which are managed by the operator or whose value does not match the type of the setting
+func validateConfig(config map[string]string) error {
+	var errs []string
+	for _, key := range sortedConfigKeys(config) {
+		if operatorManagedConfigKey(key) {
+			errs = append(errs, fmt.Sprintf("%s is managed by the operator", key))
+			continue
+		}
+		if valueType, ok := configValueTypeOf(key); ok && !valueType.valid(config[key]) {
+			errs = append(errs, fmt.Sprintf("%s must be %s, got %q", key, valueType.description, config[key]))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid spec.rabbitmq.config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ConfigWarnings returns the problems of the rabbitmq.conf settings which do not prevent the configuration from being applied:
+// settings of spec.rabbitmq.config unknown to the operator, settings set in both config and additionalConfig,
+// and settings of additionalConfig which override settings managed by the operator.
+func ConfigWarnings(instance *rabbitmqv1beta1.RabbitmqCluster) []string {
+	var warnings []string
+	config := instance.Spec.Rabbitmq.Config
+	for _, key := range sortedConfigKeys(config) {
+		if _, ok := configValueTypeOf(key); !ok && !operatorManagedConfigKey(key) {
+			warnings = append(warnings, fmt.Sprintf("spec.rabbitmq.config sets unknown setting %s", key))
+		}
+	}
+
+	// additionalConfig is not validated; it fails to render if it cannot be parsed
+	additionalConfig, err := ini.Load([]byte(instance.Spec.Rabbitmq.AdditionalConfig))
+	if err != nil {
+		return warnings
+	}
+	for _, key := range additionalConfig.Section("").KeyStrings() {
+		if operatorManagedConfigKey(key) {
+			warnings = append(warnings, fmt.Sprintf("spec.rabbitmq.additionalConfig overrides setting %s managed by the operator", key))
+		} else if _, ok := config[key]; ok {
+			warnings = append(warnings, fmt.Sprintf("spec.rabbitmq.additionalConfig overrides setting %s of spec.rabbitmq.config", key))
+		}
+	}
+	return warnings
+}
+
+func sortedConfigKeys(config map[string]string) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runtimeConfigCommands build the rabbitmqctl commands applying the rabbitmq.conf settings which RabbitMQ can change at runtime.
+// The log levels are applied together, see logLevelCommand.
+var runtimeConfigCommands = map[string]func(value string) string{
+	"vm_memory_high_watermark.relative": func(value string) string {
+		return "rabbitmqctl set_vm_memory_high_watermark " + value
+	},
+	"vm_memory_high_watermark.absolute": func(value string) string {
+		return "rabbitmqctl set_vm_memory_high_watermark absolute " + value
+	},
+	"disk_free_limit.absolute": func(value string) string {
+		return "rabbitmqctl set_disk_free_limit " + value
+	},
+	"disk_free_limit.relative": func(value string) string {
+		return "rabbitmqctl set_disk_free_limit mem_relative " + value
+	},
+	"consumer_timeout": func(value string) string {
+		return fmt.Sprintf("rabbitmqctl eval 'application:set_env(rabbit, consumer_timeout, %s).'", value)
+	},
+	"log.console.level": nil,
+	"log.file.level":    nil,
+}
+
+var logLevelKeys = []string{"log.console.level", "log.file.level"}
+
+// runtimeConfigKey returns true for settings which can be changed at runtime. The value must be valid,
+// since it is passed to rabbitmqctl.
+func runtimeConfigKey(key, value string) bool {
+	if _, ok := runtimeConfigCommands[key]; !ok {
+		return false
+	}
+	valueType, _ := configValueTypeOf(key)
+	return valueType.valid(value)
+}
+
+// ConfigRequiresRestart returns true if the change of the server ConfigMap from previous to current data
+// can only be applied by restarting the RabbitMQ nodes. This is the case if advanced.config or rabbitmq-env.conf changed,
+// or if a setting of rabbitmq.conf which cannot be changed at runtime was added, changed or removed.
+// Log levels can only be changed at runtime if the console and file log levels are the same.
+// Removing a setting which can be changed at runtime requires a restart as well, since only RabbitMQ knows its default.
+func ConfigRequiresRestart(previous, current map[string]string) bool {
+	for _, key := range []string{"advanced.config", "rabbitmq-env.conf"} {
+		if previous[key] != current[key] {
+			return true
+		}
+	}
+	previousConf, err := rabbitmqConf(previous)
+	if err != nil {
+		return true
+	}
+	currentConf, err := rabbitmqConf(current)
+	if err != nil {
+		return true
+	}
+	for key := range previousConf {
+		if _, ok := currentConf[key]; !ok {
+			return true
+		}
+	}
+	for key, value := range currentConf {
+		if previousValue, ok := previousConf[key]; ok && previousValue == value {
+			continue
+		}
+		if !runtimeConfigKey(key, value) {
+			return true
+		}
+		if _, ok := runtimeLogLevel(currentConf); !ok && (key == logLevelKeys[0] || key == logLevelKeys[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// RuntimeConfigCommands returns the rabbitmqctl commands applying the settings of the server ConfigMap data which can be changed at runtime
+func RuntimeConfigCommands(data map[string]string) ([]string, error) {
+	conf, err := rabbitmqConf(data)
+	if err != nil {
+		return nil, err
+	}
+	var commands []string
+	for _, key := range sortedConfigKeys(conf) {
+		if command := runtimeConfigCommands[key]; command != nil && runtimeConfigKey(key, conf[key]) {
+			commands = append(commands, command(conf[key]))
+		}
+	}
+	if command := logLevelCommand(conf); command != "" {
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// logLevelCommand sets the configured log level. rabbitmqctl set_log_level changes the level of all log outputs,
+// so a log output without a configured level runs with the configured level of the other output until the nodes restart.
+// No command is returned if the console and file log levels differ; changing either of them requires a restart then.
+func logLevelCommand(conf map[string]string) string {
+	level, ok := runtimeLogLevel(conf)
+	if !ok || level == "" || !runtimeConfigKey("log.console.level", level) {
+		return ""
+	}
+	return "rabbitmqctl set_log_level " + level
+}
+
+// runtimeLogLevel returns the log level configured for the console and file log outputs,
+// and false if the two log levels differ and can therefore not be set at runtime.
+func runtimeLogLevel(conf map[string]string) (string, bool) {
+	level := ""
+	for _, key := range logLevelKeys {
+		value, ok := conf[key]
+		if !ok {
+			continue
+		}
+		if level != "" && level != value {
+			return "", false
+		}
+		level = value
+	}
+	return level, true
+}
+
+// rabbitmqConf returns the effective rabbitmq.conf settings of the server ConfigMap data
+func rabbitmqConf(data map[string]string) (map[string]string, error) {
+	conf, err := ini.Load([]byte(data["operatorDefaults.conf"]))
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.Append([]byte(data["userDefinedConfiguration.conf"])); err != nil {
+		return nil, err
+	}
+	settings := map[string]string{}
+	for _, key := range conf.Section("").Keys() {
+		settings[key.Name()] = key.Value()
+	}
+	return settings, nil
+}
@@ -0,0 +1,128 @@
+// RabbitMQ Cluster Operator
+//
+// Copyright 2020 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Mozilla Public license, Version 2.0 (the "License").  You may not use this product except in compliance with the Mozilla Public License.
+//
+// This product may include a number of subcomponents with separate copyright notices and license terms. Your use of these subcomponents is subject to the terms and conditions of the subcomponent's license, as noted in the LICENSE file.
+//
+
+package resource_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+)
+
+var _ = Describe("ConfigWarnings", func() {
+	var instance *rabbitmqv1beta1.RabbitmqCluster
+
+	BeforeEach(func() {
+		instance = &rabbitmqv1beta1.RabbitmqCluster{}
+	})
+
+	It("returns no warnings for known settings", func() {
+		instance.Spec.Rabbitmq.Config = map[string]string{
+			"heartbeat":            "30",
+			"log.console.level":    "info",
+			"loopback_users.guest": "false",
+		}
+		Expect(resource.ConfigWarnings(instance)).To(BeEmpty())
+	})
+
+	It("warns about unknown settings", func() {
+		instance.Spec.Rabbitmq.Config = map[string]string{
+			"hearbeat":  "30",
+			"heartbeat": "30",
+		}
+		Expect(resource.ConfigWarnings(instance)).To(ConsistOf("spec.rabbitmq.config sets unknown setting hearbeat"))
+	})
+
+	It("warns about additionalConfig overriding settings", func() {
+		instance.Spec.Rabbitmq.Config = map[string]string{
+			"heartbeat": "30",
+		}
+		instance.Spec.Rabbitmq.AdditionalConfig = "heartbeat = 60\ncluster_formation.k8s.host = example.com\nchannel_max = 100"
+		Expect(resource.ConfigWarnings(instance)).To(ConsistOf(
+			"spec.rabbitmq.additionalConfig overrides setting heartbeat of spec.rabbitmq.config",
+			"spec.rabbitmq.additionalConfig overrides setting cluster_formation.k8s.host managed by the operator",
+		))
+	})
+})
+
+var _ = Describe("Runtime configuration", func() {
+	data := func(userDefinedConfiguration string) map[string]string {
+		return map[string]string{
+			"operatorDefaults.conf":         "cluster_name = foo\ndisk_free_limit.absolute = 2GB",
+			"userDefinedConfiguration.conf": userDefinedConfiguration,
+		}
+	}
+
+	Context("ConfigRequiresRestart", func() {
+		It("returns false if only settings which can be changed at runtime are added or changed", func() {
+			Expect(resource.ConfigRequiresRestart(
+				data("vm_memory_high_watermark.relative = 0.4"),
+				data("vm_memory_high_watermark.relative = 0.6\nlog.console.level = debug\ndisk_free_limit.absolute = 5GB"),
+			)).To(BeFalse())
+		})
+
+		It("returns true if a setting which cannot be changed at runtime is changed", func() {
+			Expect(resource.ConfigRequiresRestart(data("heartbeat = 30"), data("heartbeat = 60"))).To(BeTrue())
+			Expect(resource.ConfigRequiresRestart(data(""), data("heartbeat = 60"))).To(BeTrue())
+		})
+
+		It("returns true if a log level is changed while the console and file log levels differ", func() {
+			Expect(resource.ConfigRequiresRestart(
+				data("log.console.level = info\nlog.file.level = info"),
+				data("log.console.level = debug\nlog.file.level = info"),
+			)).To(BeTrue())
+			Expect(resource.ConfigRequiresRestart(
+				data("log.console.level = info"),
+				data("log.console.level = debug\nlog.file.level = debug"),
+			)).To(BeFalse())
+		})
+
+		It("returns true if a setting is removed", func() {
+			Expect(resource.ConfigRequiresRestart(data("log.console.level = debug"), data(""))).To(BeTrue())
+		})
+
+		It("returns true if the value of a setting which can be changed at runtime is invalid", func() {
+			Expect(resource.ConfigRequiresRestart(data(""), data("vm_memory_high_watermark.relative = 0.6 && reboot"))).To(BeTrue())
+		})
+
+		It("returns true if advanced.config or rabbitmq-env.conf changed", func() {
+			current := data("")
+			current["advanced.config"] = "[]."
+			Expect(resource.ConfigRequiresRestart(data(""), current)).To(BeTrue())
+			current = data("")
+			current["rabbitmq-env.conf"] = "FOO=bar"
+			Expect(resource.ConfigRequiresRestart(data(""), current)).To(BeTrue())
+		})
+	})
+
+	Context("RuntimeConfigCommands", func() {
+		It("returns the commands applying the settings which can be changed at runtime", func() {
+			commands, err := resource.RuntimeConfigCommands(data(`vm_memory_high_watermark.relative = 0.6
+consumer_timeout = 3600000
+heartbeat = 30
+log.console.level = info
+log.file.level = info`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(commands).To(Equal([]string{
+				"rabbitmqctl eval 'application:set_env(rabbit, consumer_timeout, 3600000).'",
+				"rabbitmqctl set_disk_free_limit 2GB",
+				"rabbitmqctl set_vm_memory_high_watermark 0.6",
+				"rabbitmqctl set_log_level info",
+			}))
+		})
+
+		It("does not set the log level if the console and file log levels differ", func() {
+			commands, err := resource.RuntimeConfigCommands(data(`log.console.level = warning
+log.file.level = info`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(commands).To(Equal([]string{"rabbitmqctl set_disk_free_limit 2GB"}))
+		})
+	})
+})
@@ -39,7 +39,7 @@ func (builder *RabbitmqPluginsConfigMapBuilder) Build() (client.Object, error) {
 			Annotations: metadata.ReconcileAndFilterAnnotations(nil, builder.Instance.Annotations),
 		},
 		Data: map[string]string{
-			"enabled_plugins": desiredPluginsAsString([]rabbitmqv1beta1.Plugin{}),
+			"enabled_plugins": desiredPluginsAsString([]rabbitmqv1beta1.Plugin{}, nil),
 		},
 	}, nil
 }
@@ -54,7 +54,7 @@ func (builder *RabbitmqPluginsConfigMapBuilder) Update(object client.Object) err
 	if configMap.Data == nil {
 		configMap.Data = make(map[string]string)
 	}
-	configMap.Data["enabled_plugins"] = desiredPluginsAsString(builder.Instance.Spec.Rabbitmq.AdditionalPlugins)
+	configMap.Data["enabled_plugins"] = desiredPluginsAsString(builder.Instance.Spec.Rabbitmq.AdditionalPlugins, builder.Instance.Spec.Rabbitmq.CommunityPlugins)
 
 	if err := controllerutil.SetControllerReference(builder.Instance, configMap, builder.Scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %v", err)
@@ -67,10 +67,13 @@ type RabbitmqPlugins struct {
 	additionalPlugins []string
 }
 
-func NewRabbitmqPlugins(plugins []rabbitmqv1beta1.Plugin) RabbitmqPlugins {
-	additionalPlugins := make([]string, len(plugins))
-	for i := range additionalPlugins {
-		additionalPlugins[i] = string(plugins[i])
+func NewRabbitmqPlugins(plugins []rabbitmqv1beta1.Plugin, communityPlugins []rabbitmqv1beta1.CommunityPlugin) RabbitmqPlugins {
+	additionalPlugins := make([]string, 0, len(plugins)+len(communityPlugins))
+	for _, p := range plugins {
+		additionalPlugins = append(additionalPlugins, string(p))
+	}
+	for _, p := range communityPlugins {
+		additionalPlugins = append(additionalPlugins, string(p.Name))
 	}
 
 	return RabbitmqPlugins{
@@ -97,7 +100,7 @@ func (r *RabbitmqPlugins) AsString(sep string) string {
 	return strings.Join(r.DesiredPlugins(), sep)
 }
 
-func desiredPluginsAsString(additionalPlugins []rabbitmqv1beta1.Plugin) string {
-	plugins := NewRabbitmqPlugins(additionalPlugins)
+func desiredPluginsAsString(additionalPlugins []rabbitmqv1beta1.Plugin, communityPlugins []rabbitmqv1beta1.CommunityPlugin) string {
+	plugins := NewRabbitmqPlugins(additionalPlugins, communityPlugins)
 	return "[" + plugins.AsString(",") + "]."
 }
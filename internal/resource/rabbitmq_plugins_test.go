@@ -27,7 +27,7 @@ var _ = Describe("RabbitMQPlugins", func() {
 	Context("DesiredPlugins", func() {
 		When("AdditionalPlugins is empty", func() {
 			It("returns list of required plugins", func() {
-				plugins := NewRabbitmqPlugins(nil)
+				plugins := NewRabbitmqPlugins(nil, nil)
 				Expect(plugins.DesiredPlugins()).To(ConsistOf([]string{"rabbitmq_peer_discovery_k8s", "rabbitmq_prometheus", "rabbitmq_management"}))
 			})
 		})
@@ -35,7 +35,7 @@ var _ = Describe("RabbitMQPlugins", func() {
 		When("AdditionalPlugins are provided", func() {
 			It("returns a concatenated list of plugins", func() {
 				morePlugins := []rabbitmqv1beta1.Plugin{"rabbitmq_shovel", "my_great_plugin"}
-				plugins := NewRabbitmqPlugins(morePlugins)
+				plugins := NewRabbitmqPlugins(morePlugins, nil)
 
 				Expect(plugins.DesiredPlugins()).To(ConsistOf([]string{"rabbitmq_peer_discovery_k8s",
 					"rabbitmq_prometheus",
@@ -49,7 +49,7 @@ var _ = Describe("RabbitMQPlugins", func() {
 		When("AdditionalPlugins are provided with duplicates", func() {
 			It("returns a unique list of plugins", func() {
 				morePlugins := []rabbitmqv1beta1.Plugin{"rabbitmq_management", "rabbitmq_shovel", "my_great_plugin", "rabbitmq_shovel"}
-				plugins := NewRabbitmqPlugins(morePlugins)
+				plugins := NewRabbitmqPlugins(morePlugins, nil)
 
 				Expect(plugins.DesiredPlugins()).To(ConsistOf([]string{"rabbitmq_peer_discovery_k8s",
 					"rabbitmq_prometheus",
@@ -59,6 +59,25 @@ var _ = Describe("RabbitMQPlugins", func() {
 				}))
 			})
 		})
+
+		When("CommunityPlugins are provided", func() {
+			It("returns a unique list of plugins including community plugins", func() {
+				morePlugins := []rabbitmqv1beta1.Plugin{"rabbitmq_shovel", "rabbitmq_message_timestamp"}
+				communityPlugins := []rabbitmqv1beta1.CommunityPlugin{
+					{Name: "rabbitmq_message_timestamp", URL: "https://example.com/rabbitmq_message_timestamp.ez"},
+					{Name: "rabbitmq_delayed_message_exchange", Image: "my-registry/plugins:1.0"},
+				}
+				plugins := NewRabbitmqPlugins(morePlugins, communityPlugins)
+
+				Expect(plugins.DesiredPlugins()).To(ConsistOf([]string{"rabbitmq_peer_discovery_k8s",
+					"rabbitmq_prometheus",
+					"rabbitmq_management",
+					"rabbitmq_shovel",
+					"rabbitmq_message_timestamp",
+					"rabbitmq_delayed_message_exchange",
+				}))
+			})
+		})
 	})
 
 	Context("PluginsConfigMap", func() {
@@ -220,6 +239,25 @@ var _ = Describe("RabbitMQPlugins", func() {
 				})
 			})
 
+			When("communityPlugins are provided in instance spec", func() {
+				It("adds community plugins to enabled_plugins", func() {
+					builder.Instance.Spec.Rabbitmq.AdditionalPlugins = []rabbitmqv1beta1.Plugin{"rabbitmq_shovel"}
+					builder.Instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+						{Name: "rabbitmq_message_timestamp", URL: "https://example.com/rabbitmq_message_timestamp.ez"},
+					}
+
+					expectedEnabledPlugins := "[" +
+						"rabbitmq_peer_discovery_k8s," +
+						"rabbitmq_prometheus," +
+						"rabbitmq_management," +
+						"rabbitmq_shovel," +
+						"rabbitmq_message_timestamp]."
+
+					Expect(configMapBuilder.Update(configMap)).To(Succeed())
+					Expect(configMap.Data).To(HaveKeyWithValue("enabled_plugins", expectedEnabledPlugins))
+				})
+			})
+
 			// ensures that we are not unnecessarily running `rabbitmq-plugins set` when CR labels are updated
 			It("does not update labels on the config map", func() {
 				configMap.Labels = map[string]string{
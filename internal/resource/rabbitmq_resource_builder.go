@@ -11,6 +11,7 @@ package resource
 
 import (
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -18,6 +19,14 @@ import (
 type RabbitmqResourceBuilder struct {
 	Instance *rabbitmqv1beta1.RabbitmqCluster
 	Scheme   *runtime.Scheme
+	// Rewrites applied to the images of all containers of the StatefulSet
+	ImageRegistryRewrites []ImageRegistryRewrite
+	// Image pull secrets added to the Pods of the StatefulSet in addition to spec.imagePullSecrets
+	DefaultImagePullSecrets []corev1.LocalObjectReference
+	// Whether the Gateway API CRDs are installed; the management UI is exposed through an HTTPRoute instead of an Ingress if so
+	GatewayAPIInstalled bool
+	// Whether the Prometheus Operator CRDs are installed; monitors are only created if so
+	PrometheusOperatorInstalled bool
 }
 
 type ResourceBuilder interface {
@@ -26,8 +35,14 @@ type ResourceBuilder interface {
 	UpdateMayRequireStsRecreate() bool
 }
 
+// OptionalResourceBuilder is implemented by builders of resources which not every RabbitmqCluster needs.
+// Resources which are not needed are deleted.
+type OptionalResourceBuilder interface {
+	Needed() bool
+}
+
 func (builder *RabbitmqResourceBuilder) ResourceBuilders() ([]ResourceBuilder, error) {
-	return []ResourceBuilder{
+	builders := []ResourceBuilder{
 		builder.HeadlessService(),
 		builder.Service(),
 		builder.ErlangCookie(),
@@ -37,6 +52,21 @@ func (builder *RabbitmqResourceBuilder) ResourceBuilders() ([]ResourceBuilder, e
 		builder.ServiceAccount(),
 		builder.Role(),
 		builder.RoleBinding(),
+		builder.PodDisruptionBudget(),
+		builder.NetworkPolicy(),
+		builder.ServiceMonitor(),
+		builder.PodMonitor(),
+		builder.ManagementIngress(),
+		builder.ManagementHTTPRoute(),
 		builder.StatefulSet(),
-	}, nil
+	}
+	if builder.Instance.Spec.Replicas != nil {
+		for i := int32(0); i < *builder.Instance.Spec.Replicas; i++ {
+			builders = append(builders, builder.PerPodService(i))
+		}
+	}
+	for _, service := range builder.Instance.Spec.Services {
+		builders = append(builders, builder.NamedService(service))
+	}
+	return builders, nil
 }
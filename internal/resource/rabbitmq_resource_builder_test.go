@@ -48,7 +48,7 @@ var _ = Describe("RabbitmqResourceBuilder", func() {
 			resourceBuilders, err := builder.ResourceBuilders()
 			Expect(err).NotTo(HaveOccurred())
 
-			expectedLen := 10
+			expectedLen := 16
 			Expect(len(resourceBuilders)).To(Equal(expectedLen))
 
 			expectedBuildersInOrder := []ResourceBuilder{
@@ -61,6 +61,12 @@ var _ = Describe("RabbitmqResourceBuilder", func() {
 				&ServiceAccountBuilder{},
 				&RoleBuilder{},
 				&RoleBindingBuilder{},
+				&PodDisruptionBudgetBuilder{},
+				&NetworkPolicyBuilder{},
+				&ServiceMonitorBuilder{},
+				&PodMonitorBuilder{},
+				&ManagementIngressBuilder{},
+				&ManagementHTTPRouteBuilder{},
 				&StatefulSetBuilder{},
 			}
 
@@ -68,5 +74,29 @@ var _ = Describe("RabbitmqResourceBuilder", func() {
 				Expect(resourceBuilders[i]).To(BeAssignableToTypeOf(expectedBuildersInOrder[i]))
 			}
 		})
+
+		It("appends a per Pod Service builder for each replica", func() {
+			three := int32(3)
+			instance.Spec.Replicas = &three
+			defer func() { instance.Spec.Replicas = nil }()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resourceBuilders).To(HaveLen(19))
+			for i := 16; i < 19; i++ {
+				Expect(resourceBuilders[i]).To(BeAssignableToTypeOf(&PerPodServiceBuilder{}))
+			}
+		})
+
+		It("appends a builder for each named Service", func() {
+			instance.Spec.Services = []rabbitmqv1beta1.RabbitmqClusterNamedServiceSpec{{Name: "amqp"}, {Name: "mqtt"}}
+			defer func() { instance.Spec.Services = nil }()
+
+			resourceBuilders, err := builder.ResourceBuilders()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resourceBuilders).To(HaveLen(18))
+			Expect(resourceBuilders[16]).To(BeAssignableToTypeOf(&NamedServiceBuilder{}))
+			Expect(resourceBuilders[17]).To(BeAssignableToTypeOf(&NamedServiceBuilder{}))
+		})
 	})
 })
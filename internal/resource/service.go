@@ -27,6 +27,8 @@ import (
 
 const (
 	ServiceSuffix = ""
+	// ClientServiceLabel is only set on the client Service, so that it can be selected apart from the other Services of the RabbitmqCluster
+	ClientServiceLabel = "rabbitmq.com/client-service"
 )
 
 type ServiceBuilder struct {
@@ -53,7 +55,9 @@ func (builder *ServiceBuilder) UpdateMayRequireStsRecreate() bool {
 func (builder *ServiceBuilder) Update(object client.Object) error {
 	service := object.(*corev1.Service)
 	builder.setAnnotations(service)
-	service.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
+	service.Labels = mergeMap(metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels), map[string]string{
+		ClientServiceLabel: "true",
+	})
 	service.Spec.Type = builder.Instance.Spec.Service.Type
 	service.Spec.Selector = metadata.LabelSelector(builder.Instance.Name)
 
@@ -381,6 +381,10 @@ var _ = Context("Services", func() {
 			It("deletes the labels that are removed from the CR", func() {
 				Expect(svc.Labels).NotTo(HaveKey("this-was-the-previous-label"))
 			})
+
+			It("labels the client Service", func() {
+				Expect(svc.Labels).To(HaveKeyWithValue("rabbitmq.com/client-service", "true"))
+			})
 		})
 
 		Context("Service Type", func() {
@@ -614,6 +618,7 @@ var _ = Context("Services", func() {
 					"app.kubernetes.io/name":      instance.Name,
 					"app.kubernetes.io/component": "rabbitmq",
 					"app.kubernetes.io/part-of":   "rabbitmq",
+					"rabbitmq.com/client-service": "true",
 					"new-label-key":               "new-label-value",
 				}))
 			})
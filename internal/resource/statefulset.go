@@ -12,6 +12,7 @@ package resource
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -35,6 +36,14 @@ const (
 	initContainerMemory string = "500Mi"
 	defaultPVCName      string = "persistence"
 	DeletionMarker      string = "skipPreStopChecks"
+	// "topology.kubernetes.io/zone" is a well-known label.
+	// It is automatically set by kubelet if the cloud provider provides the zone information.
+	// See: https://kubernetes.io/docs/reference/kubernetes-api/labels-annotations-taints/#topologykubernetesiozone
+	ZoneLabel string = "topology.kubernetes.io/zone"
+	// the zone label of the Pod, mounted if spec.topology is set
+	zoneFile string = "/etc/pod-info/zone"
+	// seconds the setup container waits for the operator to label the Pod with its zone
+	zoneTimeout int = 60
 )
 
 type StatefulSetBuilder struct {
@@ -58,7 +67,7 @@ func (builder *StatefulSetBuilder) Build() (client.Object, error) {
 			Namespace: builder.Instance.Namespace,
 		},
 		Spec: appsv1.StatefulSetSpec{
-			ServiceName: builder.Instance.ChildResourceName(headlessServiceSuffix),
+			ServiceName: builder.Instance.ChildResourceName(HeadlessServiceSuffix),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: metadata.LabelSelector(builder.Instance.Name),
 			},
@@ -107,13 +116,22 @@ func (builder *StatefulSetBuilder) UpdateMayRequireStsRecreate() bool {
 func (builder *StatefulSetBuilder) Update(object client.Object) error {
 	sts := object.(*appsv1.StatefulSet)
 
+	if err := validateCommunityPlugins(builder.Instance.Spec.Rabbitmq.CommunityPlugins); err != nil {
+		return err
+	}
+
 	//Replicas
 	sts.Spec.Replicas = builder.Instance.Spec.Replicas
 
 	//Update Strategy
+	// in a canary rollout, the partition is moved down by the operator once the updated Pods pass the health checks
+	partition := int32(0)
+	if builder.Instance.CanaryRollout() && sts.Spec.UpdateStrategy.RollingUpdate != nil && sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
 	sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
 		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
-			Partition: pointer.Int32Ptr(0),
+			Partition: pointer.Int32Ptr(partition),
 		},
 		Type: appsv1.RollingUpdateStatefulSetStrategyType,
 	}
@@ -125,7 +143,7 @@ func (builder *StatefulSetBuilder) Update(object client.Object) error {
 	sts.Labels = metadata.GetLabels(builder.Instance.Name, builder.Instance.Labels)
 
 	// PVC storage capacity
-	updatePersistenceStorageCapacity(&sts.Spec.VolumeClaimTemplates, builder.Instance.Spec.Persistence.Storage)
+	updatePersistenceStorageCapacity(&sts.Spec.VolumeClaimTemplates, builder.Instance.Spec.Persistence)
 
 	// pod template
 	sts.Spec.Template = builder.podTemplateSpec(sts.Spec.Template.Annotations)
@@ -141,16 +159,25 @@ func (builder *StatefulSetBuilder) Update(object client.Object) error {
 		}
 	}
 
+	// applied after the override, so that images set in the override are rewritten too
+	builder.applyImageRegistrySettings(&sts.Spec.Template.Spec)
+
 	if err := controllerutil.SetControllerReference(builder.Instance, sts, builder.Scheme); err != nil {
 		return fmt.Errorf("failed setting controller reference: %v", err)
 	}
 	return nil
 }
 
-func updatePersistenceStorageCapacity(templates *[]corev1.PersistentVolumeClaim, capacity *k8sresource.Quantity) {
+func updatePersistenceStorageCapacity(templates *[]corev1.PersistentVolumeClaim, persistence rabbitmqv1beta1.RabbitmqClusterPersistenceSpec) {
 	for _, t := range *templates {
 		if t.Name == defaultPVCName {
-			t.Spec.Resources.Requests[corev1.ResourceStorage] = *capacity
+			t.Spec.Resources.Requests[corev1.ResourceStorage] = *persistence.Storage
+			continue
+		}
+		for _, v := range persistence.Volumes {
+			if t.Name == string(v.Role) {
+				t.Spec.Resources.Requests[corev1.ResourceStorage] = persistenceVolumeStorage(v)
+			}
 		}
 	}
 }
@@ -208,12 +235,35 @@ func persistentVolumeClaim(instance *rabbitmqv1beta1.RabbitmqCluster, scheme *ru
 		},
 	}
 
-	if err := controllerutil.SetControllerReference(instance, &pvc, scheme); err != nil {
-		return []corev1.PersistentVolumeClaim{}, fmt.Errorf("failed setting controller reference: %v", err)
+	pvcs := []corev1.PersistentVolumeClaim{pvc}
+	for _, v := range instance.Spec.Persistence.Volumes {
+		pvcs = append(pvcs, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        string(v.Role),
+				Namespace:   instance.GetNamespace(),
+				Labels:      metadata.Label(instance.Name),
+				Annotations: metadata.ReconcileAndFilterAnnotations(map[string]string{}, instance.Annotations),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: persistenceVolumeStorage(v),
+					},
+				},
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: v.StorageClassName,
+			},
+		})
+	}
+
+	for i := range pvcs {
+		if err := controllerutil.SetControllerReference(instance, &pvcs[i], scheme); err != nil {
+			return []corev1.PersistentVolumeClaim{}, fmt.Errorf("failed setting controller reference: %v", err)
+		}
+		disableBlockOwnerDeletion(pvcs[i])
 	}
-	disableBlockOwnerDeletion(pvc)
 
-	return []corev1.PersistentVolumeClaim{pvc}, nil
+	return pvcs, nil
 }
 
 // required for OpenShift compatibility, see https://github.com/rabbitmq/cluster-operator/issues/234
@@ -407,7 +457,21 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 		},
 	}
 
-	if builder.Instance.Spec.Rabbitmq.AdvancedConfig != "" || builder.Instance.Spec.Rabbitmq.EnvConfig != "" {
+	if builder.Instance.Spec.Topology != nil {
+		// the operator copies the zone label of the Kubernetes node to the Pod
+		for _, v := range volumes {
+			if v.Name == "pod-info" {
+				v.VolumeSource.DownwardAPI.Items = append(v.VolumeSource.DownwardAPI.Items, corev1.DownwardAPIVolumeFile{
+					Path: "zone",
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: fmt.Sprintf("metadata.labels['%s']", ZoneLabel),
+					},
+				})
+			}
+		}
+	}
+
+	if builder.Instance.Spec.Rabbitmq.AdvancedConfig != "" || envConfig(builder.Instance) != "" {
 		volumes = append(volumes, corev1.Volume{
 			Name: "server-conf",
 			VolumeSource: corev1.VolumeSource{
@@ -451,7 +515,9 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 		},
 	}
 
-	if builder.Instance.Spec.Rabbitmq.EnvConfig != "" {
+	rabbitmqContainerVolumeMounts = append(rabbitmqContainerVolumeMounts, persistenceVolumeMounts(builder.Instance.Spec.Persistence.Volumes)...)
+
+	if envConfig(builder.Instance) != "" {
 		rabbitmqContainerVolumeMounts = append(rabbitmqContainerVolumeMounts, corev1.VolumeMount{
 			Name: "server-conf", MountPath: "/etc/rabbitmq/rabbitmq-env.conf", SubPath: "rabbitmq-env.conf",
 		})
@@ -505,25 +571,13 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 		volumes = append(volumes, tlsProjectedVolume)
 	}
 
-	return corev1.PodTemplateSpec{
+	podTemplateSpec := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: metadata.ReconcileAnnotations(previousPodAnnotations, defaultPodAnnotations),
 			Labels:      metadata.Label(builder.Instance.Name),
 		},
 		Spec: corev1.PodSpec{
-			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
-				{
-					MaxSkew: 1,
-					// "topology.kubernetes.io/zone" is a well-known label.
-					// It is automatically set by kubelet if the cloud provider provides the zone information.
-					// See: https://kubernetes.io/docs/reference/kubernetes-api/labels-annotations-taints/#topologykubernetesiozone
-					TopologyKey:       "topology.kubernetes.io/zone",
-					WhenUnsatisfiable: corev1.ScheduleAnyway,
-					LabelSelector: &metav1.LabelSelector{
-						MatchLabels: metadata.LabelSelector(builder.Instance.Name),
-					},
-				},
-			},
+			TopologySpreadConstraints: builder.topologySpreadConstraints(),
 			SecurityContext: &corev1.PodSecurityContext{
 				FSGroup:    &rabbitmqGID,
 				RunAsGroup: &rabbitmqGID,
@@ -559,16 +613,7 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 						},
 					},
 					Command: []string{
-						"sh", "-c", "cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie " +
-							"&& chown 999:999 /var/lib/rabbitmq/.erlang.cookie " +
-							"&& chmod 600 /var/lib/rabbitmq/.erlang.cookie ; " +
-							"cp /tmp/rabbitmq-plugins/enabled_plugins /operator/enabled_plugins " +
-							"&& chown 999:999 /operator/enabled_plugins ; " +
-							"chown 999:999 /var/lib/rabbitmq/mnesia/ ; " +
-							"echo '[default]' > /var/lib/rabbitmq/.rabbitmqadmin.conf " +
-							"&& sed -e 's/default_user/username/' -e 's/default_pass/password/' /tmp/default_user.conf >> /var/lib/rabbitmq/.rabbitmqadmin.conf " +
-							"&& chown 999:999 /var/lib/rabbitmq/.rabbitmqadmin.conf " +
-							"&& chmod 600 /var/lib/rabbitmq/.rabbitmqadmin.conf",
+						"sh", "-c", builder.setupContainerCommand(),
 					},
 					Resources: corev1.ResourceRequirements{
 						Limits: map[corev1.ResourceName]k8sresource.Quantity{
@@ -640,7 +685,7 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 						},
 						{
 							Name:  "K8S_SERVICE_NAME",
-							Value: builder.Instance.ChildResourceName(headlessServiceSuffix),
+							Value: builder.Instance.ChildResourceName(HeadlessServiceSuffix),
 						},
 						{
 							Name:  "RABBITMQ_USE_LONGNAME",
@@ -695,6 +740,171 @@ func (builder *StatefulSetBuilder) podTemplateSpec(previousPodAnnotations map[st
 			},
 		},
 	}
+
+	if volumes := builder.Instance.Spec.Persistence.Volumes; len(volumes) > 0 {
+		setupContainer := &podTemplateSpec.Spec.InitContainers[0]
+		mounts := persistenceVolumeMounts(volumes)
+		setupContainer.VolumeMounts = append(setupContainer.VolumeMounts, mounts...)
+		chown := ""
+		for _, m := range mounts {
+			if c := builder.chown(m.MountPath); c != "" {
+				chown += c + " ; "
+			}
+		}
+		setupContainer.Command[len(setupContainer.Command)-1] = chown + setupContainer.Command[len(setupContainer.Command)-1]
+
+		rabbitmqContainer := &podTemplateSpec.Spec.Containers[0]
+		rabbitmqContainer.Env = append(rabbitmqContainer.Env, persistenceVolumeEnvVars(volumes)...)
+	}
+
+	if builder.Instance.Spec.Topology != nil {
+		// the setup container waits for the zone of the Pod
+		setupContainer := &podTemplateSpec.Spec.InitContainers[0]
+		setupContainer.VolumeMounts = append(setupContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      "pod-info",
+			MountPath: "/etc/pod-info/",
+		})
+	}
+
+	if communityPlugins := builder.Instance.Spec.Rabbitmq.CommunityPlugins; len(communityPlugins) > 0 {
+		setupContainer := &podTemplateSpec.Spec.InitContainers[0]
+		setupContainer.Command[len(setupContainer.Command)-1] += communityPluginsSetupCommand(communityPlugins, builder.Instance.Spec.Security == nil)
+		podTemplateSpec.Spec.InitContainers = append(
+			communityPluginInitContainers(communityPlugins, setupContainer.Resources),
+			podTemplateSpec.Spec.InitContainers...)
+
+		rabbitmqContainer := &podTemplateSpec.Spec.Containers[0]
+		rabbitmqContainer.Env = append(rabbitmqContainer.Env, corev1.EnvVar{
+			Name:  "RABBITMQ_PLUGINS_DIR",
+			Value: fmt.Sprintf("%s:%s", defaultPluginsDir, communityPluginsDir),
+		})
+	}
+
+	if builder.Instance.Spec.Security != nil {
+		builder.setRestrictedSecurityContexts(&podTemplateSpec)
+	}
+
+	return podTemplateSpec
+}
+
+// setupContainerCommand copies the Erlang cookie, the enabled plugins, and the rabbitmqadmin configuration into place.
+// The setup container runs as root and changes the owner of the files to the RabbitMQ user unless spec.security is set.
+func (builder *StatefulSetBuilder) setupContainerCommand() string {
+	commands := []string{
+		andThen("cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie",
+			builder.chown("/var/lib/rabbitmq/.erlang.cookie"),
+			"chmod 600 /var/lib/rabbitmq/.erlang.cookie"),
+		andThen("cp /tmp/rabbitmq-plugins/enabled_plugins /operator/enabled_plugins",
+			builder.chown("/operator/enabled_plugins")),
+		builder.chown("/var/lib/rabbitmq/mnesia/"),
+		andThen("echo '[default]' > /var/lib/rabbitmq/.rabbitmqadmin.conf",
+			"sed -e 's/default_user/username/' -e 's/default_pass/password/' /tmp/default_user.conf >> /var/lib/rabbitmq/.rabbitmqadmin.conf",
+			builder.chown("/var/lib/rabbitmq/.rabbitmqadmin.conf"),
+			"chmod 600 /var/lib/rabbitmq/.rabbitmqadmin.conf"),
+	}
+	if builder.Instance.Spec.Topology != nil {
+		// the operator labels the Pod with its zone once it is scheduled; RabbitMQ reads the zone when it starts
+		commands = append(commands, fmt.Sprintf("i=0; until [ -s %s ] || [ $i -ge %d ]; do sleep 1; i=$((i+1)); done", zoneFile, zoneTimeout))
+	}
+	return joinNonEmpty(" ; ", commands...)
+}
+
+// chown returns the command changing the owner of the path to the RabbitMQ user of the image,
+// or an empty string if spec.security is set since the setup container does not run as root then
+func (builder *StatefulSetBuilder) chown(path string) string {
+	if builder.Instance.Spec.Security != nil {
+		return ""
+	}
+	return fmt.Sprintf("chown 999:999 %s", path)
+}
+
+func andThen(commands ...string) string {
+	return joinNonEmpty(" && ", commands...)
+}
+
+func joinNonEmpty(separator string, commands ...string) string {
+	var nonEmpty []string
+	for _, c := range commands {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, separator)
+}
+
+// setRestrictedSecurityContexts makes the Pod comply with the restricted Pod Security Standard.
+// In FixedUID mode, the Pod runs with the configured user and group; in PlatformAssigned mode, the platform assigns them.
+func (builder *StatefulSetBuilder) setRestrictedSecurityContexts(podTemplateSpec *corev1.PodTemplateSpec) {
+	security := builder.Instance.Spec.Security
+	podSecurityContext := &corev1.PodSecurityContext{
+		RunAsNonRoot: pointer.BoolPtr(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	if security.Mode != rabbitmqv1beta1.PlatformAssignedSecurityMode {
+		uid := int64(999)
+		if security.RunAsUser != nil {
+			uid = *security.RunAsUser
+		}
+		gid := int64(999)
+		if security.RunAsGroup != nil {
+			gid = *security.RunAsGroup
+		}
+		fsGroupChangePolicy := corev1.FSGroupChangeOnRootMismatch
+		podSecurityContext.RunAsUser = &uid
+		podSecurityContext.RunAsGroup = &gid
+		podSecurityContext.FSGroup = &gid
+		podSecurityContext.FSGroupChangePolicy = &fsGroupChangePolicy
+	}
+	podTemplateSpec.Spec.SecurityContext = podSecurityContext
+
+	for _, containers := range [][]corev1.Container{podTemplateSpec.Spec.InitContainers, podTemplateSpec.Spec.Containers} {
+		for i := range containers {
+			containers[i].SecurityContext = &corev1.SecurityContext{
+				AllowPrivilegeEscalation: pointer.BoolPtr(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			}
+		}
+	}
+}
+
+// topologySpreadConstraints spreads the Pods across zones if possible, unless spec.topology.zones configures the spread.
+// The Pods are only spread across hosts if spec.topology.hosts is set.
+func (builder *StatefulSetBuilder) topologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	topology := builder.Instance.Spec.Topology
+	zones := rabbitmqv1beta1.TopologySpreadSpec{}
+	if topology != nil && topology.Zones != nil {
+		zones = *topology.Zones
+	}
+
+	constraints := []corev1.TopologySpreadConstraint{
+		builder.topologySpreadConstraint(ZoneLabel, zones),
+	}
+	if topology != nil && topology.Hosts != nil {
+		constraints = append(constraints, builder.topologySpreadConstraint(corev1.LabelHostname, *topology.Hosts))
+	}
+	return constraints
+}
+
+func (builder *StatefulSetBuilder) topologySpreadConstraint(topologyKey string, spread rabbitmqv1beta1.TopologySpreadSpec) corev1.TopologySpreadConstraint {
+	constraint := corev1.TopologySpreadConstraint{
+		MaxSkew:           spread.MaxSkew,
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: spread.WhenUnsatisfiable,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name),
+		},
+	}
+	if constraint.MaxSkew == 0 {
+		constraint.MaxSkew = 1
+	}
+	if constraint.WhenUnsatisfiable == "" {
+		constraint.WhenUnsatisfiable = corev1.ScheduleAnyway
+	}
+	return constraint
 }
 
 func (builder *StatefulSetBuilder) updateContainerPorts() []corev1.ContainerPort {
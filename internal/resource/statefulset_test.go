@@ -147,6 +147,46 @@ var _ = Describe("StatefulSet", func() {
 				actualPersistentVolumeClaim := statefulSet.Spec.VolumeClaimTemplates[0]
 				Expect(actualPersistentVolumeClaim).To(Equal(expectedPersistentVolumeClaim))
 			})
+
+			It("creates a PersistentVolumeClaim for every additional volume", func() {
+				walStorage := k8sresource.MustParse("5Gi")
+				walStorageClass := "fast"
+				instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+					{
+						Role:             rabbitmqv1beta1.PersistenceVolumeQuorumWAL,
+						StorageClassName: &walStorageClass,
+						Storage:          &walStorage,
+					},
+					{
+						Role: rabbitmqv1beta1.PersistenceVolumeLogs,
+					},
+				}
+
+				obj, err := stsBuilder.Build()
+				Expect(err).NotTo(HaveOccurred())
+				statefulSet := obj.(*appsv1.StatefulSet)
+
+				templates := statefulSet.Spec.VolumeClaimTemplates
+				Expect(templates).To(HaveLen(3))
+				Expect(templates[0].Name).To(Equal("persistence"))
+
+				Expect(templates[1].Name).To(Equal("quorum-wal"))
+				Expect(templates[1].Namespace).To(Equal(instance.Namespace))
+				Expect(templates[1].Spec.StorageClassName).To(Equal(&walStorageClass))
+				Expect(templates[1].Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(walStorage))
+				Expect(templates[1].Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+				Expect(templates[1].OwnerReferences).To(ConsistOf(v1.OwnerReference{
+					APIVersion:         "rabbitmq.com/v1beta1",
+					Kind:               "RabbitmqCluster",
+					Name:               instance.Name,
+					Controller:         pointer.BoolPtr(true),
+					BlockOwnerDeletion: pointer.BoolPtr(false),
+				}))
+
+				Expect(templates[2].Name).To(Equal("logs"))
+				Expect(templates[2].Spec.StorageClassName).To(BeNil())
+				Expect(templates[2].Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(k8sresource.MustParse("10Gi")))
+			})
 		})
 		Context("Override", func() {
 			It("overrides statefulSet.spec.selector", func() {
@@ -437,6 +477,19 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.UpdateStrategy).To(Equal(updateStrategy))
 		})
 
+		It("resets the partition of a rolling update", func() {
+			statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(2)}
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+			Expect(*statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(0)))
+		})
+
+		It("keeps the partition of a canary rollout", func() {
+			statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(2)}
+			stsBuilder.Instance.Spec.Rollout = &rabbitmqv1beta1.RabbitmqClusterRolloutSpec{Mode: rabbitmqv1beta1.CanaryRolloutMode}
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+			Expect(*statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
+		})
+
 		It("updates toleration", func() {
 			newToleration := corev1.Toleration{
 				Key:      "update",
@@ -917,6 +970,88 @@ var _ = Describe("StatefulSet", func() {
 				}))
 		})
 
+		Context("topology", func() {
+			BeforeEach(func() {
+				instance.Spec.Topology = &rabbitmqv1beta1.RabbitmqClusterTopologySpec{
+					Zones: &rabbitmqv1beta1.TopologySpreadSpec{WhenUnsatisfiable: corev1.DoNotSchedule},
+					Hosts: &rabbitmqv1beta1.TopologySpreadSpec{MaxSkew: 2},
+				}
+			})
+
+			It("spreads the Pods across zones and hosts", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				selector := &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app.kubernetes.io/name": instance.Name},
+				}
+				Expect(statefulSet.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(
+					corev1.TopologySpreadConstraint{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+						LabelSelector:     selector,
+					},
+					corev1.TopologySpreadConstraint{
+						MaxSkew:           2,
+						TopologyKey:       "kubernetes.io/hostname",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+						LabelSelector:     selector,
+					}))
+			})
+
+			It("keeps the default spread across zones", func() {
+				instance.Spec.Topology = &rabbitmqv1beta1.RabbitmqClusterTopologySpec{}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(
+					corev1.TopologySpreadConstraint{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"app.kubernetes.io/name": instance.Name},
+						},
+					}))
+			})
+
+			It("waits for the zone of the Pod in the setup container", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				setupContainer := statefulSet.Spec.Template.Spec.InitContainers[0]
+				Expect(setupContainer.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "pod-info", MountPath: "/etc/pod-info/"}))
+				Expect(setupContainer.Command[2]).To(HaveSuffix(" ; i=0; until [ -s /etc/pod-info/zone ] || [ $i -ge 60 ]; do sleep 1; i=$((i+1)); done"))
+			})
+
+			It("passes the zone to RabbitMQ in rabbitmq-env.conf", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				rabbitmqContainer := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(rabbitmqContainer.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "server-conf",
+					MountPath: "/etc/rabbitmq/rabbitmq-env.conf",
+					SubPath:   "rabbitmq-env.conf",
+				}))
+			})
+
+			It("mounts the zone of the Pod", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				var podInfo *corev1.Volume
+				for i, v := range statefulSet.Spec.Template.Spec.Volumes {
+					if v.Name == "pod-info" {
+						podInfo = &statefulSet.Spec.Template.Spec.Volumes[i]
+					}
+				}
+				Expect(podInfo).NotTo(BeNil())
+				Expect(podInfo.DownwardAPI.Items).To(ContainElement(corev1.DownwardAPIVolumeFile{
+					Path: "zone",
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.labels['topology.kubernetes.io/zone']",
+					},
+				}))
+			})
+		})
+
 		It("has resources requirements on the init container", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -1200,6 +1335,72 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.Template.Spec.SecurityContext).To(Equal(expectedPodSecurityContext))
 		})
 
+		When("spec.security is set", func() {
+			var stsBuilder *resource.StatefulSetBuilder
+
+			BeforeEach(func() {
+				instance.Spec.Security = &rabbitmqv1beta1.RabbitmqClusterSecuritySpec{}
+				instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+					{Role: rabbitmqv1beta1.PersistenceVolumeLogs},
+				}
+				instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+					{Name: "a-plugin", Image: "a-plugin-image", SHA256: "abc"},
+				}
+				stsBuilder = builder.StatefulSet()
+			})
+
+			It("runs all containers without privilege escalation and capabilities", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				restricted := &corev1.SecurityContext{
+					AllowPrivilegeEscalation: pointer.BoolPtr(false),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				}
+				Expect(statefulSet.Spec.Template.Spec.InitContainers).To(HaveLen(2))
+				for _, c := range append(statefulSet.Spec.Template.Spec.InitContainers, statefulSet.Spec.Template.Spec.Containers...) {
+					Expect(c.SecurityContext).To(Equal(restricted), c.Name)
+				}
+			})
+
+			It("does not change the owner of any file in the setup container", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				Expect(setupContainer.Command[2]).To(HavePrefix("cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie " +
+					"&& chmod 600 /var/lib/rabbitmq/.erlang.cookie ; " +
+					"cp /tmp/rabbitmq-plugins/enabled_plugins /operator/enabled_plugins ; " +
+					"echo '[default]' > /var/lib/rabbitmq/.rabbitmqadmin.conf " +
+					"&& sed -e 's/default_user/username/' -e 's/default_pass/password/' /tmp/default_user.conf >> /var/lib/rabbitmq/.rabbitmqadmin.conf " +
+					"&& chmod 600 /var/lib/rabbitmq/.rabbitmqadmin.conf ; mkdir -p /operator/community-plugins"))
+				Expect(setupContainer.Command[2]).NotTo(ContainSubstring("chown"))
+			})
+
+			It("runs as the given user and group in FixedUID mode", func() {
+				instance.Spec.Security.RunAsUser = pointer.Int64Ptr(1001)
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				onRootMismatch := corev1.FSGroupChangeOnRootMismatch
+				Expect(statefulSet.Spec.Template.Spec.SecurityContext).To(Equal(&corev1.PodSecurityContext{
+					RunAsNonRoot:        pointer.BoolPtr(true),
+					RunAsUser:           pointer.Int64Ptr(1001),
+					RunAsGroup:          pointer.Int64Ptr(999),
+					FSGroup:             pointer.Int64Ptr(999),
+					FSGroupChangePolicy: &onRootMismatch,
+					SeccompProfile:      &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				}))
+			})
+
+			It("leaves user, group, and fsGroup to the platform in PlatformAssigned mode", func() {
+				instance.Spec.Security.Mode = rabbitmqv1beta1.PlatformAssignedSecurityMode
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.SecurityContext).To(Equal(&corev1.PodSecurityContext{
+					RunAsNonRoot:   pointer.BoolPtr(true),
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				}))
+			})
+		})
+
 		It("defines a Readiness Probe", func() {
 			stsBuilder := builder.StatefulSet()
 			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
@@ -1307,6 +1508,196 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal(expectedPreStopCommand))
 		})
 
+		Context("additional volumes", func() {
+			BeforeEach(func() {
+				instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+					{Role: rabbitmqv1beta1.PersistenceVolumeQuorumWAL},
+					{Role: rabbitmqv1beta1.PersistenceVolumeQuorumSegments},
+					{Role: rabbitmqv1beta1.PersistenceVolumeStreamSegments},
+					{Role: rabbitmqv1beta1.PersistenceVolumeLogs},
+				}
+			})
+
+			It("mounts the volumes in the rabbitmq and setup containers", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				expectedMounts := []corev1.VolumeMount{
+					{Name: "quorum-wal", MountPath: "/var/lib/rabbitmq/quorum-wal/"},
+					{Name: "quorum-segments", MountPath: "/var/lib/rabbitmq/quorum-segments/"},
+					{Name: "stream-segments", MountPath: "/var/lib/rabbitmq/stream/"},
+					{Name: "logs", MountPath: "/var/log/rabbitmq/"},
+				}
+				container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(container.VolumeMounts).To(ContainElements(expectedMounts))
+				setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				Expect(setupContainer.VolumeMounts).To(ContainElements(expectedMounts))
+			})
+
+			It("changes the owner of the volumes in the setup container", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				Expect(setupContainer.Command[2]).To(HavePrefix("chown 999:999 /var/lib/rabbitmq/quorum-wal/ ; " +
+					"chown 999:999 /var/lib/rabbitmq/quorum-segments/ ; " +
+					"chown 999:999 /var/lib/rabbitmq/stream/ ; " +
+					"chown 999:999 /var/log/rabbitmq/ ; " +
+					"cp /tmp/erlang-cookie-secret/.erlang.cookie /var/lib/rabbitmq/.erlang.cookie "))
+			})
+
+			It("configures RabbitMQ to use the volumes", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(container.Env).To(ContainElements(
+					corev1.EnvVar{Name: "RABBITMQ_QUORUM_DIR", Value: "/var/lib/rabbitmq/quorum-segments/"},
+					corev1.EnvVar{Name: "RABBITMQ_STREAM_DIR", Value: "/var/lib/rabbitmq/stream/"},
+					corev1.EnvVar{Name: "RABBITMQ_LOG_BASE", Value: "/var/log/rabbitmq/"},
+					corev1.EnvVar{Name: "RABBITMQ_LOGS", Value: "/var/log/rabbitmq/rabbit.log"},
+				))
+				for _, envVar := range container.Env {
+					Expect(envVar.Name).NotTo(Equal("RABBITMQ_SERVER_ADDITIONAL_ERL_ARGS"))
+				}
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name: "server-conf", MountPath: "/etc/rabbitmq/rabbitmq-env.conf", SubPath: "rabbitmq-env.conf",
+				}))
+			})
+		})
+
+		Context("community plugins", func() {
+			const (
+				urlChecksum   = "4f1d6bd5f5a5bfe8ec3a0a9e0e1d4a3c3ba5b2a1b0c9d8e7f6a5b4c3d2e1f0a9"
+				imageChecksum = "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"
+			)
+
+			BeforeEach(func() {
+				instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+					{
+						Name:   "rabbitmq_message_timestamp",
+						URL:    "https://example.com/rabbitmq_message_timestamp-3.8.0.ez",
+						SHA256: urlChecksum,
+					},
+					{
+						Name:   "rabbitmq_delayed_message_exchange",
+						Image:  "my-registry/rabbitmq-plugins:1.0",
+						Path:   "/plugins/rabbitmq_delayed_message_exchange-3.8.0.ez",
+						SHA256: imageChecksum,
+					},
+				}
+			})
+
+			It("downloads plugins with a URL and verifies checksums of all plugins in the setup container", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				setupContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "setup-container")
+				Expect(setupContainer.Command).To(HaveLen(3))
+				Expect(setupContainer.Command[2]).To(HaveSuffix(" ; mkdir -p /operator/community-plugins" +
+					" && erl -noinput -eval 'try {ok, _} = application:ensure_all_started(inets), {ok, _} = application:ensure_all_started(ssl), " +
+					"{ok, saved_to_file} = httpc:request(get, {\"https://example.com/rabbitmq_message_timestamp-3.8.0.ez\", []}, [{timeout, 300000}], " +
+					"[{stream, \"/operator/community-plugins/rabbitmq_message_timestamp.ez\"}]), halt(0) " +
+					"catch C:R -> io:format(\"failed to download community plugin: ~p:~p~n\", [C, R]), halt(1) end.'" +
+					" && echo '" + urlChecksum + "  /operator/community-plugins/rabbitmq_message_timestamp.ez' | sha256sum -c -" +
+					" && echo '" + imageChecksum + "  /operator/community-plugins/rabbitmq_delayed_message_exchange.ez' | sha256sum -c -" +
+					" && chown -R 999:999 /operator/community-plugins"))
+			})
+
+			It("copies plugins from images in init containers running before the setup container", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				initContainers := statefulSet.Spec.Template.Spec.InitContainers
+				Expect(initContainers).To(HaveLen(2))
+				Expect(initContainers[0].Name).To(Equal("copy-community-plugin-1"))
+				Expect(initContainers[0].Image).To(Equal("my-registry/rabbitmq-plugins:1.0"))
+				Expect(initContainers[0].Command).To(Equal([]string{"sh", "-c",
+					"mkdir -p /operator/community-plugins && cp /plugins/rabbitmq_delayed_message_exchange-3.8.0.ez /operator/community-plugins/rabbitmq_delayed_message_exchange.ez"}))
+				Expect(initContainers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "rabbitmq-plugins", MountPath: "/operator"}))
+				Expect(initContainers[0].Resources.Limits["memory"]).To(Equal(k8sresource.MustParse("500Mi")))
+				Expect(initContainers[1].Name).To(Equal("setup-container"))
+			})
+
+			It("defaults the path of the plugin within the image", func() {
+				instance.Spec.Rabbitmq.CommunityPlugins[1].Path = ""
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				initContainer := extractContainer(statefulSet.Spec.Template.Spec.InitContainers, "copy-community-plugin-1")
+				Expect(initContainer.Command[2]).To(ContainSubstring("cp /plugins/rabbitmq_delayed_message_exchange.ez "))
+			})
+
+			It("adds the community plugins directory to the plugins directories of RabbitMQ", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				container := extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq")
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{
+					Name:  "RABBITMQ_PLUGINS_DIR",
+					Value: "/opt/rabbitmq/plugins:/operator/community-plugins",
+				}))
+			})
+
+			It("returns an error if a plugin sets both url and image", func() {
+				instance.Spec.Rabbitmq.CommunityPlugins[0].Image = "my-registry/rabbitmq-plugins:1.0"
+				Expect(stsBuilder.Update(statefulSet)).To(MatchError("community plugin rabbitmq_message_timestamp must set exactly one of url or image"))
+			})
+
+			It("returns an error if a plugin sets neither url nor image", func() {
+				instance.Spec.Rabbitmq.CommunityPlugins[1].Image = ""
+				Expect(stsBuilder.Update(statefulSet)).To(MatchError("community plugin rabbitmq_delayed_message_exchange must set exactly one of url or image"))
+			})
+		})
+
+		Context("image registry settings", func() {
+			BeforeEach(func() {
+				instance.Spec.Image = "rabbitmq:3.8.16-management"
+				instance.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "my-secret"}}
+				instance.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{{
+					Name:   "rabbitmq_delayed_message_exchange",
+					Image:  "quay.io/my-org/rabbitmq-plugins:1.0",
+					SHA256: "0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9",
+				}}
+				builder.ImageRegistryRewrites = []resource.ImageRegistryRewrite{
+					{From: "docker.io/", To: "mirror.example.com/dockerhub/"},
+					{From: "quay.io/", To: "mirror.example.com/quay/"},
+					{From: "quay.io/my-org/", To: "mirror.example.com/my-org/"},
+				}
+				builder.DefaultImagePullSecrets = []corev1.LocalObjectReference{{Name: "mirror-credentials"}, {Name: "my-secret"}}
+			})
+
+			It("rewrites the images of all containers by the longest matching prefix", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				podSpec := statefulSet.Spec.Template.Spec
+				Expect(extractContainer(podSpec.Containers, "rabbitmq").Image).To(Equal("mirror.example.com/dockerhub/library/rabbitmq:3.8.16-management"))
+				Expect(extractContainer(podSpec.InitContainers, "setup-container").Image).To(Equal("mirror.example.com/dockerhub/library/rabbitmq:3.8.16-management"))
+				Expect(extractContainer(podSpec.InitContainers, "copy-community-plugin-0").Image).To(Equal("mirror.example.com/my-org/rabbitmq-plugins:1.0"))
+			})
+
+			It("rewrites images set in the StatefulSet override", func() {
+				instance.Spec.Override.StatefulSet = &rabbitmqv1beta1.StatefulSet{
+					Spec: &rabbitmqv1beta1.StatefulSetSpec{
+						Template: &rabbitmqv1beta1.PodTemplateSpec{
+							Spec: &corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "sidecar", Image: "docker.io/my-org/sidecar:1.0"}},
+							},
+						},
+					},
+				}
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(extractContainer(statefulSet.Spec.Template.Spec.Containers, "sidecar").Image).To(Equal("mirror.example.com/dockerhub/my-org/sidecar:1.0"))
+			})
+
+			It("adds the default image pull secrets", func() {
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(statefulSet.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "my-secret"}, {Name: "mirror-credentials"}}))
+			})
+
+			It("does not change images without matching prefix", func() {
+				instance.Spec.Image = "registry.example.com/rabbitmq:3.8.16"
+				Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+
+				Expect(extractContainer(statefulSet.Spec.Template.Spec.Containers, "rabbitmq").Image).To(Equal("registry.example.com/rabbitmq:3.8.16"))
+			})
+		})
+
 		Context("resources requirements", func() {
 			It("sets StatefulSet resource requirements", func() {
 				instance.Spec.Resources = &corev1.ResourceRequirements{
@@ -1413,6 +1804,30 @@ var _ = Describe("StatefulSet", func() {
 			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]).To(Equal(newCapacity))
 		})
 
+		It("updates the storage capacity of additional volumes", func() {
+			defaultCapacity := k8sresource.MustParse("10Gi")
+			newCapacity := k8sresource.MustParse("21Gi")
+			newWALCapacity := k8sresource.MustParse("30Gi")
+			for _, name := range []string{"persistence", "quorum-wal"} {
+				statefulSet.Spec.VolumeClaimTemplates = append(statefulSet.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{
+					ObjectMeta: v1.ObjectMeta{Name: name, Namespace: instance.Namespace},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.ResourceRequirements{
+							Requests: map[corev1.ResourceName]k8sresource.Quantity{corev1.ResourceStorage: defaultCapacity},
+						},
+					},
+				})
+			}
+
+			stsBuilder.Instance.Spec.Persistence.Storage = &newCapacity
+			stsBuilder.Instance.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+				{Role: rabbitmqv1beta1.PersistenceVolumeQuorumWAL, Storage: &newWALCapacity},
+			}
+			Expect(stsBuilder.Update(statefulSet)).To(Succeed())
+			Expect(statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]).To(Equal(newCapacity))
+			Expect(statefulSet.Spec.VolumeClaimTemplates[1].Spec.Resources.Requests["storage"]).To(Equal(newWALCapacity))
+		})
+
 		When("stateful set override are provided", func() {
 			It("overrides statefulSet.ObjectMeta.Annotations", func() {
 				instance.Annotations = map[string]string{
@@ -10,6 +10,8 @@
 package status
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -19,7 +21,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func NoWarningsCondition(resources []runtime.Object, oldCondition *RabbitmqClusterCondition) RabbitmqClusterCondition {
+// NoWarningsCondition reports warnings about the StatefulSet first, then the given configuration warnings, then the Pods
+// whose RabbitMQ nodes are drained
+func NoWarningsCondition(resources []runtime.Object, oldCondition *RabbitmqClusterCondition, drainedNodes []string, configWarnings ...string) RabbitmqClusterCondition {
 	condition := newRabbitmqClusterCondition(NoWarnings)
 	if oldCondition != nil {
 		condition.LastTransitionTime = oldCondition.LastTransitionTime
@@ -42,6 +46,20 @@ func NoWarningsCondition(resources []runtime.Object, oldCondition *RabbitmqClust
 				goto assignLastTransitionTime
 			}
 
+			if len(configWarnings) > 0 {
+				condition.Status = corev1.ConditionFalse
+				condition.Reason = "ConfigurationWarnings"
+				condition.Message = strings.Join(configWarnings, "; ")
+				goto assignLastTransitionTime
+			}
+
+			if len(drainedNodes) > 0 {
+				condition.Status = corev1.ConditionFalse
+				condition.Reason = "NodesDrained"
+				condition.Message = fmt.Sprintf("nodes of Pods %s are in maintenance mode", strings.Join(drainedNodes, ", "))
+				goto assignLastTransitionTime
+			}
+
 			condition.Status = corev1.ConditionTrue
 			condition.Reason = "NoWarnings"
 		}
@@ -38,7 +38,7 @@ var _ = Describe("NoWarnings", func() {
 				},
 			},
 		}
-		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil)
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil, nil)
 		By("having the correct type", func() {
 			var conditionType rabbitmqstatus.RabbitmqClusterConditionType = "NoWarnings"
 			Expect(condition.Type).To(Equal(conditionType))
@@ -52,7 +52,7 @@ var _ = Describe("NoWarnings", func() {
 	})
 
 	It("is false if the memory request does not match the memory limit", func() {
-		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, nil)
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, nil, nil)
 		By("having the correct type", func() {
 			var conditionType rabbitmqstatus.RabbitmqClusterConditionType = "NoWarnings"
 			Expect(condition.Type).To(Equal(conditionType))
@@ -65,9 +65,31 @@ var _ = Describe("NoWarnings", func() {
 		})
 	})
 
+	It("is false if there are configuration warnings", func() {
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, nil, nil, "first warning", "second warning")
+
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ConfigurationWarnings"))
+		Expect(condition.Message).To(Equal("first warning; second warning"))
+	})
+
+	It("is false if nodes are drained", func() {
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, nil, []string{"server-0", "server-2"})
+
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("NodesDrained"))
+		Expect(condition.Message).To(Equal("nodes of Pods server-0, server-2 are in maintenance mode"))
+	})
+
+	It("reports configuration warnings before drained nodes", func() {
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, nil, []string{"server-0"}, "a warning")
+
+		Expect(condition.Reason).To(Equal("ConfigurationWarnings"))
+	})
+
 	It("is unknown when the StatefulSet does not exist", func() {
 		var sts *appsv1.StatefulSet = nil
-		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil)
+		condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{sts}, nil, nil)
 
 		By("having status unknown and reason", func() {
 			Expect(condition.Status).To(Equal(corev1.ConditionUnknown))
@@ -98,7 +120,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("remains true", func() {
 				It("does not update transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -108,7 +130,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to false", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -119,7 +141,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to unknown", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -141,7 +163,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to true", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{noMemoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -152,7 +174,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("remains false", func() {
 				It("does not update transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -162,7 +184,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to unknown", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -184,7 +206,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to true", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -197,7 +219,7 @@ var _ = Describe("NoWarnings", func() {
 
 				It("updates transition time", func() {
 
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -208,7 +230,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("remains unknown", func() {
 				It("does not update transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition, nil)
 
 					Expect(existingCondition).NotTo(BeNil())
 					existingConditionTime := existingCondition.LastTransitionTime.DeepCopy()
@@ -228,7 +250,7 @@ var _ = Describe("NoWarnings", func() {
 			When("transitions to true", func() {
 
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(condition.LastTransitionTime).ToNot(Equal(emptyTime))
 				})
@@ -236,7 +258,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to false", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{memoryWarningStatefulSet()}, existingCondition, nil)
 
 					Expect(condition.LastTransitionTime).ToNot(Equal(emptyTime))
 				})
@@ -244,7 +266,7 @@ var _ = Describe("NoWarnings", func() {
 
 			When("transitions to unknown", func() {
 				It("updates transition time", func() {
-					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition)
+					condition := rabbitmqstatus.NoWarningsCondition([]runtime.Object{nil}, existingCondition, nil)
 
 					Expect(condition.LastTransitionTime).ToNot(Equal(emptyTime))
 				})
@@ -0,0 +1,23 @@
+package status_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/rabbitmq/cluster-operator/internal/status"
+)
+
+var _ = Describe("RolloutPaused", func() {
+
+	It("has the required fields", func() {
+		condition := RolloutPausedCondition(corev1.ConditionTrue, "HealthCheckFailed", "SomeMessage")
+		Expect(condition.Type).To(Equal(RabbitmqClusterConditionType("RolloutPaused")))
+		Expect(condition.Status).To(Equal(corev1.ConditionStatus("True")))
+		Expect(condition.Reason).To(Equal("HealthCheckFailed"))
+		Expect(condition.Message).To(Equal("SomeMessage"))
+		emptyTime := metav1.Time{}
+		Expect(condition.LastTransitionTime).NotTo(Equal(emptyTime))
+	})
+})
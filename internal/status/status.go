@@ -23,6 +23,7 @@ const (
 	ClusterAvailable RabbitmqClusterConditionType = "ClusterAvailable"
 	NoWarnings       RabbitmqClusterConditionType = "NoWarnings"
 	ReconcileSuccess RabbitmqClusterConditionType = "ReconcileSuccess"
+	RolloutPaused    RabbitmqClusterConditionType = "RolloutPaused"
 )
 
 type RabbitmqClusterConditionType string
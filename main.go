@@ -9,10 +9,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
@@ -21,15 +23,29 @@ import (
 
 	rabbitmqv1beta1 "github.com/rabbitmq/cluster-operator/api/v1beta1"
 	"github.com/rabbitmq/cluster-operator/controllers"
+	"github.com/rabbitmq/cluster-operator/internal/config"
+	"github.com/rabbitmq/cluster-operator/internal/resource"
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	defaultscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
 )
 
-const controllerName = "rabbitmqcluster-controller"
+const (
+	controllerName = "rabbitmqcluster-controller"
+	// how often the operator configuration file is checked for changes
+	configWatchInterval = 30 * time.Second
+)
 
 var (
 	scheme = runtime.NewScheme()
@@ -43,32 +59,71 @@ func init() {
 }
 
 func main() {
-	var metricsAddr string
-	flag.StringVar(&metricsAddr, "metrics-addr", ":9782", "The address the metric endpoint binds to.")
+	var metricsAddr, configFile string
+	flag.StringVar(&metricsAddr, "metrics-addr", ":9782", "The address the metric endpoint binds to. Takes precedence over metricsBindAddress of the configuration file if set.")
+	flag.StringVar(&configFile, "config", "", "Path of the operator configuration file. Defaults are used if not set.")
 
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 
 	flag.Parse()
 
+	operatorConfig := config.Default()
+	var configErr error
+	logLevel := uberzap.NewAtomicLevel()
+	if configFile != "" {
+		if operatorConfig, configErr = config.Load(configFile); configErr == nil {
+			logLevel.SetLevel(zapLevel(operatorConfig.LogLevel))
+			// the log level of the configuration file takes precedence over --zap-log-level
+			opts.Level = logLevel
+		}
+	}
+
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if configErr != nil {
+		log.Error(configErr, "unable to load operator configuration", "path", configFile)
+		os.Exit(1)
+	}
+	if !flagSet("metrics-addr") {
+		metricsAddr = operatorConfig.MetricsBindAddress
+	}
+
 	operatorNamespace := os.Getenv("OPERATOR_NAMESPACE")
 	if operatorNamespace == "" {
 		log.Info("unable to find operator namespace")
 		os.Exit(1)
 	}
 
-	// If the environment variable is not set Getenv returns an empty string which ctrl.Options.Namespace takes to mean all namespaces should be watched
-	operatorScopeNamespace := os.Getenv("OPERATOR_SCOPE_NAMESPACE")
+	restConfig := ctrl.GetConfigOrDie()
+	watchNamespaces, err := getWatchNamespaces(restConfig, operatorConfig)
+	if err != nil {
+		log.Error(err, "unable to determine namespaces to watch")
+		os.Exit(1)
+	}
 
 	options := ctrl.Options{
 		Scheme:                  scheme,
 		MetricsBindAddress:      metricsAddr,
+		HealthProbeBindAddress:  operatorConfig.HealthProbeBindAddress,
+		SyncPeriod:              &operatorConfig.SyncPeriod.Duration,
 		LeaderElection:          true,
 		LeaderElectionNamespace: operatorNamespace,
 		LeaderElectionID:        "rabbitmq-cluster-operator-leader-election",
-		Namespace:               operatorScopeNamespace,
+		// StorageClasses and Nodes are cluster scoped and cannot be read from a namespaced cache
+		ClientDisableCacheFor: []client.Object{&storagev1.StorageClass{}, &corev1.Node{}},
+	}
+
+	// An empty namespace is taken by ctrl.Options.Namespace to mean all namespaces should be watched
+	switch len(watchNamespaces) {
+	case 0:
+		log.Info("manager configured to watch all namespaces")
+	case 1:
+		log.Info("manager configured to watch a single namespace", "namespace", watchNamespaces[0])
+		options.Namespace = watchNamespaces[0]
+	default:
+		log.Info("manager configured to watch multiple namespaces", "namespaces", watchNamespaces)
+		options.NewCache = cache.MultiNamespacedCacheBuilder(watchNamespaces)
 	}
 
 	if leaseDuration := getEnvInDuration("LEASE_DURATION"); leaseDuration != 0 {
@@ -86,12 +141,34 @@ func main() {
 		options.RetryPeriod = &retryPeriod
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+	mgr, err := ctrl.NewManager(restConfig, options)
 	if err != nil {
 		log.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		log.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		log.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	if configFile != "" {
+		if err := mgr.Add(&config.Watcher{
+			Path:        configFile,
+			Interval:    configWatchInterval,
+			Running:     operatorConfig,
+			SetLogLevel: func(level string) { logLevel.SetLevel(zapLevel(level)) },
+			Log:         ctrl.Log.WithName("config"),
+		}); err != nil {
+			log.Error(err, "unable to watch operator configuration")
+			os.Exit(1)
+		}
+	}
+
 	var clusterConfig *rest.Config
 	if kubeConfigPath := os.Getenv("KUBE_CONFIG"); kubeConfigPath != "" {
 		clusterConfig, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
@@ -112,6 +189,13 @@ func main() {
 		ClusterConfig: clusterConfig,
 		Clientset:     kubernetes.NewForConfigOrDie(clusterConfig),
 		PodExecutor:   controllers.NewPodExecutor(),
+
+		MaxConcurrentReconciles:    operatorConfig.Controller.MaxConcurrentReconciles,
+		RateLimiter:                operatorConfig.Controller.RateLimiter.New(),
+		DefaultRabbitmqImage:       operatorConfig.DefaultImages.RabbitMQ,
+		ImageRegistryRewrites:      imageRegistryRewrites(operatorConfig.ImageRegistry),
+		DefaultImagePullSecrets:    imagePullSecrets(operatorConfig.ImageRegistry),
+		NodesStatusRefreshInterval: operatorConfig.Controller.NodesStatusRefreshInterval.Duration,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		log.Error(err, "unable to create controller", controllerName)
@@ -127,6 +211,78 @@ func main() {
 	}
 }
 
+// getWatchNamespaces returns the namespaces to watch; all namespaces are watched if none are returned.
+// The comma separated list of namespaces in OPERATOR_SCOPE_NAMESPACE takes precedence over the operator configuration.
+func getWatchNamespaces(restConfig *rest.Config, operatorConfig *config.OperatorConfiguration) ([]string, error) {
+	if scope := os.Getenv("OPERATOR_SCOPE_NAMESPACE"); scope != "" {
+		var namespaces []string
+		for _, ns := range strings.Split(scope, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		return namespaces, nil
+	}
+	if operatorConfig.WatchNamespaceSelector == "" {
+		return operatorConfig.WatchNamespaces, nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	namespaceList, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{LabelSelector: operatorConfig.WatchNamespaceSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces matching %s: %w", operatorConfig.WatchNamespaceSelector, err)
+	}
+	if len(namespaceList.Items) == 0 {
+		return nil, fmt.Errorf("no namespace matches %s", operatorConfig.WatchNamespaceSelector)
+	}
+	var namespaces []string
+	for _, ns := range namespaceList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
+
+func imageRegistryRewrites(registry config.ImageRegistryConfiguration) []resource.ImageRegistryRewrite {
+	var rewrites []resource.ImageRegistryRewrite
+	for _, r := range registry.Rewrites {
+		rewrites = append(rewrites, resource.ImageRegistryRewrite{From: r.From, To: r.To})
+	}
+	return rewrites
+}
+
+func imagePullSecrets(registry config.ImageRegistryConfiguration) []corev1.LocalObjectReference {
+	var secrets []corev1.LocalObjectReference
+	for _, name := range registry.PullSecrets {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: name})
+	}
+	return secrets
+}
+
+// flagSet returns true if the flag was passed on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func zapLevel(level string) zapcore.Level {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel
+	case "error":
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
 func getEnvInDuration(envName string) time.Duration {
 	var durationInt int64
 	if durationStr := os.Getenv(envName); durationStr != "" {
@@ -354,6 +354,65 @@ CONSOLE_LOG=new`
 		})
 	})
 
+	Context("Multiple data volumes", func() {
+		var cluster *rabbitmqv1beta1.RabbitmqCluster
+
+		BeforeEach(func() {
+			cluster = newRabbitmqCluster(namespace, "multiple-volumes")
+			walStorage := k8sresource.MustParse("1Gi")
+			segmentsStorage := k8sresource.MustParse("2Gi")
+			cluster.Spec.Persistence.Volumes = []rabbitmqv1beta1.PersistenceVolume{
+				{
+					Role:             rabbitmqv1beta1.PersistenceVolumeQuorumWAL,
+					StorageClassName: pointer.StringPtr(storageClassName),
+					Storage:          &walStorage,
+				},
+				{
+					Role:    rabbitmqv1beta1.PersistenceVolumeQuorumSegments,
+					Storage: &segmentsStorage,
+				},
+			}
+			Expect(createRabbitmqCluster(ctx, rmqClusterClient, cluster)).To(Succeed())
+			waitForRabbitmqRunning(cluster)
+		})
+
+		AfterEach(func() {
+			Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+		})
+
+		It("stores quorum queue data on the additional volumes", func() {
+			output, err := kubectlExec(namespace, statefulSetPodName(cluster, 0), "rabbitmq", "rabbitmqctl", "environment")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(output)).To(ContainSubstring(`{wal_data_dir,"/var/lib/rabbitmq/quorum-wal/"}`))
+			Expect(string(output)).To(ContainSubstring(`{data_dir,"/var/lib/rabbitmq/quorum-segments/"}`))
+
+			for _, template := range []string{"quorum-wal", "quorum-segments"} {
+				pvcName := template + "-" + statefulSetPodName(cluster, 0)
+				pvc, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pvc.OwnerReferences).To(HaveLen(1))
+				Expect(pvc.OwnerReferences[0].Name).To(Equal(cluster.Name))
+			}
+
+			if os.Getenv("SUPPORT_VOLUME_EXPANSION") == "false" {
+				Skip("SUPPORT_VOLUME_EXPANSION is set to false; skipping volume expansion")
+			}
+
+			By("expanding an additional volume", func() {
+				newCapacity := k8sresource.MustParse("3Gi")
+				Expect(updateRabbitmqCluster(ctx, rmqClusterClient, cluster.Name, cluster.Namespace, func(cluster *rabbitmqv1beta1.RabbitmqCluster) {
+					cluster.Spec.Persistence.Volumes[0].Storage = &newCapacity
+				})).To(Succeed())
+
+				Eventually(func() k8sresource.Quantity {
+					pvc, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, "quorum-wal-"+statefulSetPodName(cluster, 0), metav1.GetOptions{})
+					Expect(err).ToNot(HaveOccurred())
+					return pvc.Spec.Resources.Requests["storage"]
+				}, 180, 5).Should(Equal(newCapacity))
+			})
+		})
+	})
+
 	Context("Clustering", func() {
 		When("RabbitmqCluster is deployed with 3 nodes", func() {
 			var cluster *rabbitmqv1beta1.RabbitmqCluster
@@ -534,6 +593,40 @@ CONSOLE_LOG=new`
 		})
 	})
 
+	When("community plugins are configured", func() {
+		var (
+			cluster    *rabbitmqv1beta1.RabbitmqCluster
+			stopServer func()
+			pluginName = "rabbitmq_system_test_plugin"
+		)
+
+		BeforeEach(func() {
+			url, checksum, cleanup := serveCommunityPlugin(ctx, clientSet, namespace, pluginName)
+			stopServer = cleanup
+
+			cluster = newRabbitmqCluster(namespace, "community-plugins")
+			cluster.Spec.Rabbitmq.CommunityPlugins = []rabbitmqv1beta1.CommunityPlugin{
+				{
+					Name:   rabbitmqv1beta1.Plugin(pluginName),
+					URL:    url,
+					SHA256: checksum,
+				},
+			}
+			Expect(createRabbitmqCluster(ctx, rmqClusterClient, cluster)).To(Succeed())
+			waitForRabbitmqRunning(cluster)
+		})
+
+		AfterEach(func() {
+			Expect(rmqClusterClient.Delete(context.TODO(), cluster)).To(Succeed())
+			stopServer()
+		})
+
+		It("downloads and enables the plugin", func() {
+			_, err := kubectlExec(namespace, statefulSetPodName(cluster, 0), "rabbitmq", "rabbitmq-plugins", "is_enabled", pluginName)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	When("(web) MQTT, STOMP, and stream plugins are enabled", func() {
 		var (
 			cluster  *rabbitmqv1beta1.RabbitmqCluster
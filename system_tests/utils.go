@@ -10,10 +10,13 @@
 package system_tests
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -989,3 +992,62 @@ func pod(ctx context.Context, clientSet *kubernetes.Clientset, r *rabbitmqv1beta
 	}, 10).Should(Succeed())
 	return pod
 }
+
+// serveCommunityPlugin builds a minimal RabbitMQ plugin archive and serves it from an HTTP server running in the cluster.
+// It returns the URL and the SHA-256 checksum of the plugin archive, and a function to delete the HTTP server.
+func serveCommunityPlugin(ctx context.Context, clientSet *kubernetes.Clientset, namespace, pluginName string) (string, string, func()) {
+	archive := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(archive)
+	appFile, err := zipWriter.Create(fmt.Sprintf("%s-0.1.0/ebin/%s.app", pluginName, pluginName))
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	_, err = fmt.Fprintf(appFile, `{application, %s, [{description, "system test plugin"}, {vsn, "0.1.0"}, {modules, []}, {registered, []}, {applications, [kernel, stdlib, rabbit]}, {env, []}]}.`, pluginName)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, zipWriter.Close()).To(Succeed())
+	checksum := sha256.Sum256(archive.Bytes())
+
+	name := strings.ReplaceAll(pluginName, "_", "-") + "-server"
+	labels := map[string]string{"app": name}
+	_, err = clientSet.CoreV1().ConfigMaps(namespace).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		BinaryData: map[string][]byte{pluginName + ".ez": archive.Bytes()},
+	}, metav1.CreateOptions{})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	_, err = clientSet.CoreV1().Pods(namespace).Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:         "nginx",
+					Image:        "nginx:stable-alpine",
+					Ports:        []corev1.ContainerPort{{ContainerPort: 80}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "plugin", MountPath: "/usr/share/nginx/html"}},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "plugin",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	_, err = clientSet.CoreV1().Services(namespace).Create(ctx, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Port: 80}},
+		},
+	}, metav1.CreateOptions{})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	cleanup := func() {
+		Expect(clientSet.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{})).To(Succeed())
+		Expect(clientSet.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})).To(Succeed())
+		Expect(clientSet.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{})).To(Succeed())
+	}
+	url := fmt.Sprintf("http://%s.%s.svc/%s.ez", name, namespace, pluginName)
+	return url, hex.EncodeToString(checksum[:]), cleanup
+}